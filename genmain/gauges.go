@@ -0,0 +1,41 @@
+package genmain
+
+import (
+	"context"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/Shopify/goose/statsd"
+)
+
+// NewGaugeCollectorComponent creates a Component that records the gauges registered with statsd.RegisterGaugeFunc
+// every interval, until it is killed.
+func NewGaugeCollectorComponent(interval time.Duration) Component {
+	return &gaugeCollectorComponent{interval: interval}
+}
+
+type gaugeCollectorComponent struct {
+	tomb     tomb.Tomb
+	interval time.Duration
+}
+
+func (c *gaugeCollectorComponent) Tomb() *tomb.Tomb {
+	return &c.tomb
+}
+
+func (c *gaugeCollectorComponent) Run() error {
+	ctx := context.Background()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.tomb.Dying():
+			return nil
+		case <-ticker.C:
+			statsd.SampleGaugeFuncs(ctx)
+		}
+	}
+}
@@ -86,6 +86,18 @@ func inverseDependencies(components []Component) map[Component][]Component {
 			inverse[dep] = append(inverse[dep], component)
 		}
 	}
+
+	// Components to be killed last implicitly depend on all the others.
+	for _, component := range components {
+		if _, ok := component.(lastComponent); !ok {
+			continue
+		}
+		for _, other := range components {
+			if _, ok := other.(lastComponent); !ok {
+				inverse[component] = append(inverse[component], other)
+			}
+		}
+	}
 	return inverse
 }
 
@@ -110,6 +122,15 @@ func isDead(t *tomb.Tomb) bool {
 	}
 }
 
+func isDying(t *tomb.Tomb) bool {
+	select {
+	case <-t.Dying():
+		return true
+	default:
+		return false
+	}
+}
+
 // Kill will terminate all running components with a given reason
 func (m *Main) Kill(reason error) {
 	// Acquire the lock to ensure the first call's `err` is the one that all components
@@ -146,6 +167,7 @@ func (m *Main) Kill(reason error) {
 					WithField("mainComponent", componentName(component)).
 					Error("component took too long to shut down")
 			}
+			m.closeLastComponents(alive, reason)
 			return
 		}
 
@@ -169,6 +191,25 @@ func (m *Main) Kill(reason error) {
 	}
 }
 
+// closeLastComponents closes the components to be killed last which weren't killed yet, when the others failed to
+// shut down before the deadline. For example, the statsd backend is still flushed and closed.
+func (m *Main) closeLastComponents(alive []Component, reason error) {
+	for _, component := range alive {
+		c, ok := component.(lastComponent)
+		if !ok || isDying(c.Tomb()) {
+			// Components killed in time are closing already, waiting for them would exceed the deadline.
+			continue
+		}
+
+		c.Tomb().Kill(reason)
+		if err := c.closeNow(); err != nil {
+			log(nil, err).
+				WithField("mainComponent", componentName(component)).
+				Error("unable to close component")
+		}
+	}
+}
+
 func (m *Main) SetShutdownDeadline(d time.Duration) {
 	m.shutdownDeadline = d
 }
@@ -206,6 +247,13 @@ func (m *Main) RunAndWait() error {
 		shutdown <- &SignalError{sig}
 	})
 
+	statsd.SendEvent(ctx, statsd.Event{
+		Title:          "genmain started",
+		Text:           fmt.Sprintf("started %d components", len(m.components)),
+		AggregationKey: "genmain",
+		AlertType:      statsd.EventAlertTypeInfo,
+	})
+
 	for _, c := range m.components {
 		safely.Run(c)
 
@@ -220,6 +268,7 @@ func (m *Main) RunAndWait() error {
 	}
 
 	reason := <-shutdown
+	statsd.SendEvent(ctx, shutdownEvent(reason))
 	m.Kill(reason)
 
 	log(nil, nil).Debug("final shutdown message")
@@ -227,6 +276,26 @@ func (m *Main) RunAndWait() error {
 	return reason
 }
 
+// shutdownEvent describes why Main is shutting down.
+// Shutdowns which were requested or caused by a signal are informational, the others are errors.
+func shutdownEvent(reason error) statsd.Event {
+	e := statsd.Event{
+		Title:          "genmain shutting down",
+		Text:           "shutting down",
+		AggregationKey: "genmain",
+		AlertType:      statsd.EventAlertTypeInfo,
+	}
+	if reason != nil {
+		e.Text = fmt.Sprintf("shutting down: %s", reason)
+	}
+
+	var signalErr *SignalError
+	if reason != nil && !errors.Is(reason, ErrShutdownRequested) && !errors.As(reason, &signalErr) {
+		e.AlertType = statsd.EventAlertTypeError
+	}
+	return e
+}
+
 func componentName(comp Component) string {
 	compType := fmt.Sprintf("%T", comp)
 	return strings.TrimPrefix(compType, "*")
@@ -1,6 +1,7 @@
 package genmain_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"syscall"
@@ -12,6 +13,8 @@ import (
 	"gopkg.in/tomb.v2"
 
 	"github.com/Shopify/goose/genmain"
+	"github.com/Shopify/goose/metrics"
+	"github.com/Shopify/goose/statsd"
 )
 
 type testComponent struct {
@@ -255,3 +258,137 @@ func runAndPerform(t *testing.T, perform func(*genmain.Main, *testComponent)) (*
 
 	return &main, component.tomb.Err()
 }
+
+type closeRecordingBackend struct {
+	*statsd.RecordingBackend
+	closed       chan struct{}
+	metricsAtEnd int
+}
+
+func (b *closeRecordingBackend) Close() error {
+	b.metricsAtEnd = len(b.Metrics())
+	close(b.closed)
+	return nil
+}
+
+func TestStatsdComponent(t *testing.T) {
+	defer statsd.SetBackend(statsd.NewNullBackend())
+
+	backend := &closeRecordingBackend{RecordingBackend: statsd.NewRecordingBackend(), closed: make(chan struct{})}
+	statsd.SetBackend(backend)
+
+	// Records a metric while shutting down, which must be submitted before the backend is closed.
+	component := &shutdownMetricComponent{
+		testComponent: newTestComponent(),
+		metric:        &statsd.Counter{Name: "shutdown"},
+	}
+	main := genmain.New(genmain.NewStatsdComponent(), component)
+
+	done := make(chan error)
+	go func() {
+		done <- main.RunAndWait()
+	}()
+	<-component.started
+
+	main.Kill(errors.New("reason"))
+
+	select {
+	case <-backend.closed:
+	case <-time.After(time.Second):
+		t.Fatal("statsd backend wasn't closed")
+	}
+
+	assert.Equal(t, int64(1), backend.CountTotal("shutdown"))
+	assert.Len(t, backend.Distributions(metrics.GenMainShutdown.Name, "mainComponent:genmain_test.shutdownMetricComponent"), 1)
+	assert.Len(t, backend.Metrics(), backend.metricsAtEnd, "no metric should be recorded after closing")
+	assert.True(t, statsd.GetBackend() != statsd.Backend(backend), "backend must be replaced once closed")
+	assert.EqualError(t, <-done, "reason")
+}
+
+func TestStatsdComponent_deadline(t *testing.T) {
+	defer statsd.SetBackend(statsd.NewNullBackend())
+
+	backend := &closeRecordingBackend{RecordingBackend: statsd.NewRecordingBackend(), closed: make(chan struct{})}
+	statsd.SetBackend(backend)
+
+	deadline := 100 * time.Millisecond
+	main := genmain.New(genmain.NewStatsdComponent(), newHangingComponent(deadline*10))
+	main.SetShutdownDeadline(deadline)
+	go main.RunAndWait()
+
+	main.Kill(errors.New("reason"))
+
+	select {
+	case <-backend.closed:
+	default:
+		t.Fatal("statsd backend wasn't closed when Kill returned")
+	}
+	assert.Len(t, backend.Distributions(metrics.GenMainShutdown.Name, "mainComponent:genmain_test.hangingComponent"), 1)
+}
+
+type shutdownMetricComponent struct {
+	*testComponent
+	metric *statsd.Counter
+}
+
+func (c *shutdownMetricComponent) Run() error {
+	err := c.testComponent.Run()
+	c.metric.Incr(context.Background())
+	return err
+}
+
+func TestGaugeCollectorComponent(t *testing.T) {
+	recorder := statsd.WithTestBackend(t)
+
+	sampled := make(chan struct{}, 1)
+	defer statsd.RegisterGaugeFunc("collected", nil, func() float64 {
+		select {
+		case sampled <- struct{}{}:
+		default:
+		}
+		return 1
+	})()
+
+	collector := genmain.NewGaugeCollectorComponent(time.Millisecond)
+	main := genmain.New(collector)
+
+	done := make(chan error)
+	go func() {
+		done <- main.RunAndWait()
+	}()
+
+	select {
+	case <-sampled:
+	case <-time.After(time.Second):
+		t.Fatal("gauges weren't sampled")
+	}
+
+	main.Kill(nil)
+	require.NoError(t, <-done)
+	assert.NotEmpty(t, recorder.Gauges("collected"))
+}
+
+func TestMainEvents(t *testing.T) {
+	recorder := statsd.WithTestBackend(t)
+
+	component := newTestComponent()
+	main := genmain.New(component)
+
+	done := make(chan error)
+	go func() {
+		done <- main.RunAndWait()
+	}()
+	<-component.started
+
+	main.Kill(genmain.ErrShutdownRequested)
+	require.ErrorIs(t, <-done, genmain.ErrShutdownRequested)
+
+	started := recorder.Events("genmain started")
+	require.Len(t, started, 1)
+	assert.Equal(t, "started 1 components", started[0].Text)
+
+	shutdown := recorder.Events("genmain shutting down")
+	require.Len(t, shutdown, 1)
+	assert.Equal(t, statsd.EventAlertTypeInfo, shutdown[0].AlertType)
+	assert.Equal(t, "shutting down: shutdown requested", shutdown[0].Text)
+}
@@ -0,0 +1,50 @@
+package genmain
+
+import (
+	"sync"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/Shopify/goose/statsd"
+)
+
+// lastComponent is implemented by components which must be killed after all the others.
+type lastComponent interface {
+	Component
+	// closeNow releases the resources of the component without waiting for Run, such that they are released
+	// even when the other components fail to shut down before the deadline. It is only called once Dying.
+	closeNow() error
+}
+
+// NewStatsdComponent creates a Component that flushes and closes the current statsd backend when killed.
+//
+// `Main` kills it after all the other components have shut down, such that the metrics they record while shutting
+// down are submitted. Metrics recorded afterwards are discarded, see statsd.Close.
+// If the other components don't shut down before the deadline, the backend is still flushed and closed.
+func NewStatsdComponent() Component {
+	return &statsdComponent{}
+}
+
+type statsdComponent struct {
+	tomb tomb.Tomb
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (c *statsdComponent) Tomb() *tomb.Tomb {
+	return &c.tomb
+}
+
+func (c *statsdComponent) Run() error {
+	<-c.tomb.Dying()
+	return c.closeNow()
+}
+
+func (c *statsdComponent) closeNow() error {
+	c.closeOnce.Do(func() {
+		log(nil, nil).Debug("closing statsd backend")
+		c.closeErr = statsd.Close()
+	})
+	return c.closeErr
+}
@@ -0,0 +1,258 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogLevelsEnv is the environment variable setting per-component levels when the package is initialized,
+// formatted as "component=level,component=level", for example "shell=debug,srvutil=warn".
+const LogLevelsEnv = "LOG_LEVELS"
+
+// componentLevels holds the levels overriding the default level, per component name as passed to New.
+//
+// The loggers created by New check the level of their component before creating an entry. The entries of the
+// components less verbose than the standard logger are created on a gateLogger, which drops the entries below the
+// level of the component before firing any hook. Since the standard logger drops the entries below its level, its
+// level is lowered to the most verbose of the default level and the overrides.
+var componentLevels = struct {
+	// Serializes the updates, the loggers load the current levelSettings without locking.
+	sync.Mutex
+	current atomic.Pointer[levelSettings]
+}{}
+
+// levelSettings are the levels applied by the last update, which aren't modified once applied.
+type levelSettings struct {
+	components map[string]logrus.Level
+	// defaultLevel is the level of the entries without an override, while the level of the standard logger is
+	// lowered to loweredLevel.
+	defaultLevel logrus.Level
+	loweredLevel logrus.Level
+	lowered      bool
+}
+
+func init() {
+	if env := os.Getenv(LogLevelsEnv); env != "" {
+		levels, err := ParseComponentLevels(env)
+		if err != nil {
+			log(nil, err).WithField("env", LogLevelsEnv).Warn("ignoring invalid component log levels")
+			return
+		}
+		SetComponentLevels(levels)
+	}
+}
+
+// ParseComponentLevels parses levels formatted as "component=level,component=level".
+func ParseComponentLevels(s string) (map[string]logrus.Level, error) {
+	levels := map[string]logrus.Level{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		component, levelName, ok := strings.Cut(pair, "=")
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid component log level %q, expected component=level", pair)
+		}
+		level, err := logrus.ParseLevel(levelName)
+		if err != nil {
+			return nil, err
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
+
+// FormatComponentLevels formats levels as "component=level,component=level", sorted by component.
+func FormatComponentLevels(levels map[string]logrus.Level) string {
+	pairs := make([]string, 0, len(levels))
+	for component, level := range levels {
+		pairs = append(pairs, component+"="+level.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// SetComponentLevel overrides the default level for the loggers created by New with the component name.
+// It takes effect immediately, including for the loggers already created.
+func SetComponentLevel(component string, level logrus.Level) {
+	updateLevels(func(s *levelSettings) {
+		s.components = copyLevels(s.components, 1)
+		s.components[component] = level
+	})
+}
+
+// ResetComponentLevel removes the level override of a component, which then uses the default level.
+func ResetComponentLevel(component string) {
+	updateLevels(func(s *levelSettings) {
+		s.components = copyLevels(s.components, 0)
+		delete(s.components, component)
+	})
+}
+
+// SetComponentLevels replaces all the level overrides.
+func SetComponentLevels(levels map[string]logrus.Level) {
+	copied := copyLevels(levels, 0)
+	updateLevels(func(s *levelSettings) {
+		s.components = copied
+	})
+}
+
+// SetLevels sets the default level and replaces all the level overrides at once, see SetDefaultLevel and
+// SetComponentLevels.
+func SetLevels(defaultLevel logrus.Level, levels map[string]logrus.Level) {
+	copied := copyLevels(levels, 0)
+	updateLevels(func(s *levelSettings) {
+		s.defaultLevel = defaultLevel
+		s.components = copied
+	})
+}
+
+// ComponentLevels returns a copy of the level overrides.
+func ComponentLevels() map[string]logrus.Level {
+	return copyLevels(currentLevels().components, 0)
+}
+
+// DefaultLevel returns the level of the entries without a component level override.
+// It is the level of the standard logger, unless lowered for a more verbose component, see SetDefaultLevel.
+func DefaultLevel() logrus.Level {
+	return currentLevels().currentDefaultLevel()
+}
+
+// SetDefaultLevel sets the level of the entries without a component level override.
+//
+// It replaces logrus.SetLevel when component levels are used: the level of the standard logger is lowered to the
+// most verbose override, such that the entries of that component aren't dropped by logrus. Setting the level with
+// logrus.SetLevel instead disables the overrides more verbose than it.
+//
+// Only the loggers created by New apply the default level. The entries logged with logrus directly are filtered
+// by the level of the standard logger, hence are logged at the level of the most verbose override.
+func SetDefaultLevel(level logrus.Level) {
+	updateLevels(func(s *levelSettings) {
+		s.defaultLevel = level
+	})
+}
+
+func currentLevels() *levelSettings {
+	if s := componentLevels.current.Load(); s != nil {
+		return s
+	}
+	return &levelSettings{}
+}
+
+// currentDefaultLevel returns the default level, which is the level of the standard logger when it wasn't lowered,
+// or was set with logrus.SetLevel since.
+func (s *levelSettings) currentDefaultLevel() logrus.Level {
+	level := logrus.GetLevel()
+	if s.lowered && level == s.loweredLevel {
+		return s.defaultLevel
+	}
+	return level
+}
+
+// level returns the level of the component.
+func (s *levelSettings) level(component string) logrus.Level {
+	if level, ok := s.components[component]; ok {
+		return level
+	}
+	return s.currentDefaultLevel()
+}
+
+// updateLevels applies the update to a copy of the current levelSettings, then sets the level of the standard logger
+// to the most verbose of the default level and the overrides.
+func updateLevels(update func(s *levelSettings)) {
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	current := currentLevels()
+	s := &levelSettings{
+		components:   current.components,
+		defaultLevel: current.currentDefaultLevel(),
+	}
+	update(s)
+
+	level := s.defaultLevel
+	for _, l := range s.components {
+		if l > level {
+			level = l
+		}
+	}
+	logrus.SetLevel(level)
+	s.loweredLevel = level
+	s.lowered = level != s.defaultLevel
+	componentLevels.current.Store(s)
+}
+
+func copyLevels(levels map[string]logrus.Level, extra int) map[string]logrus.Level {
+	copied := make(map[string]logrus.Level, len(levels)+extra)
+	for component, level := range levels {
+		copied[component] = level
+	}
+	return copied
+}
+
+// componentEntry returns the entry the Logger of the component builds upon.
+//
+// It is an entry of the standard logger, unless the component is less verbose than the standard logger. Its entries
+// are then created on the gateLogger of its level, which drops the entries below it.
+func componentEntry(component string) *logrus.Entry {
+	std := logrus.StandardLogger()
+	if level := currentLevels().level(component); level < std.GetLevel() {
+		return logrus.NewEntry(gateLoggers[level])
+	}
+	return logrus.NewEntry(std)
+}
+
+// gateLoggers are the loggers of each level, indexed by level. They don't write the entries, but forward them to the
+// standard logger with forwardHook, such that only the standard logger fires its hooks and writes to its output.
+var gateLoggers = func() []*logrus.Logger {
+	loggers := make([]*logrus.Logger, len(logrus.AllLevels))
+	for _, level := range logrus.AllLevels {
+		hooks := logrus.LevelHooks{}
+		hooks.Add(forwardHook{})
+		loggers[level] = &logrus.Logger{
+			Out:       io.Discard,
+			Formatter: discardFormatter{},
+			Hooks:     hooks,
+			Level:     level,
+			ExitFunc:  exitStandardLogger,
+		}
+	}
+	return loggers
+}()
+
+// forwardHook logs the entries of a gateLogger with the standard logger.
+type forwardHook struct{}
+
+func (forwardHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (forwardHook) Fire(entry *logrus.Entry) error {
+	forwarded := entry.Dup()
+	forwarded.Logger = logrus.StandardLogger()
+	forwarded.Log(entry.Level, entry.Message)
+	return nil
+}
+
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// exitStandardLogger exits like the standard logger would on Fatal, the exit handlers already ran.
+func exitStandardLogger(code int) {
+	if exit := logrus.StandardLogger().ExitFunc; exit != nil {
+		exit(code)
+		return
+	}
+	os.Exit(code)
+}
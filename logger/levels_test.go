@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComponentLevels(t *testing.T) {
+	levels, err := ParseComponentLevels("shell=debug, srvutil=warn,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]logrus.Level{"shell": logrus.DebugLevel, "srvutil": logrus.WarnLevel}, levels)
+	assert.Equal(t, "shell=debug,srvutil=warning", FormatComponentLevels(levels))
+
+	_, err = ParseComponentLevels("shell")
+	assert.EqualError(t, err, `invalid component log level "shell", expected component=level`)
+
+	_, err = ParseComponentLevels("shell=loud")
+	assert.Error(t, err)
+}
+
+func TestComponentLevels(t *testing.T) {
+	defer SetComponentLevels(ComponentLevels())
+
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+
+	buf := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(buf)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	verbose := New("verbose")
+	quiet := New("quiet")
+	other := New("other")
+	ctx := context.Background()
+
+	SetComponentLevels(map[string]logrus.Level{"verbose": logrus.DebugLevel})
+	SetComponentLevel("quiet", logrus.WarnLevel)
+
+	verbose(ctx).Debug("verbose debug")
+	quiet(ctx).Info("quiet info")
+	quiet(ctx).Warn("quiet warn")
+	other(ctx).Debug("other debug")
+	other(ctx).Info("other info")
+
+	assert.Contains(t, buf.String(), "verbose debug")
+	assert.NotContains(t, buf.String(), "quiet info")
+	assert.Contains(t, buf.String(), "quiet warn")
+	assert.NotContains(t, buf.String(), "other debug")
+	assert.Contains(t, buf.String(), "other info")
+
+	buf.Reset()
+	ResetComponentLevel("verbose")
+	verbose(ctx).Debug("verbose debug")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, map[string]logrus.Level{"quiet": logrus.WarnLevel}, ComponentLevels())
+}
+
+func TestDefaultLevel(t *testing.T) {
+	defer SetComponentLevels(ComponentLevels())
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+
+	logrus.SetLevel(logrus.InfoLevel)
+	SetComponentLevels(map[string]logrus.Level{"verbose": logrus.DebugLevel})
+
+	// The standard logger is lowered to let the verbose component through
+	assert.Equal(t, logrus.InfoLevel, DefaultLevel())
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+
+	SetDefaultLevel(logrus.WarnLevel)
+	assert.Equal(t, logrus.WarnLevel, DefaultLevel())
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+
+	SetDefaultLevel(logrus.TraceLevel)
+	assert.Equal(t, logrus.TraceLevel, DefaultLevel())
+	assert.Equal(t, logrus.TraceLevel, logrus.GetLevel())
+
+	// Setting the level with logrus takes precedence
+	SetDefaultLevel(logrus.InfoLevel)
+	logrus.SetLevel(logrus.ErrorLevel)
+	assert.Equal(t, logrus.ErrorLevel, DefaultLevel())
+
+	SetComponentLevels(nil)
+	assert.Equal(t, logrus.ErrorLevel, logrus.GetLevel())
+}
+
+func TestComponentLevels_concurrent(t *testing.T) {
+	defer SetComponentLevels(ComponentLevels())
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+
+	// All the entries are written by the standard logger, such that writes are serialized.
+	buf := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(buf)
+	logrus.SetLevel(logrus.InfoLevel)
+	SetComponentLevel("verbose", logrus.DebugLevel)
+
+	verbose := New("verbose")
+	other := New("other")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			verbose(context.Background()).Debug("verbose debug")
+		}()
+		go func() {
+			defer wg.Done()
+			other(context.Background()).Info("other info")
+			other(context.Background()).Debug("other debug")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, bytes.Count(buf.Bytes(), []byte("verbose debug")))
+	assert.Equal(t, 10, bytes.Count(buf.Bytes(), []byte("other info")))
+	assert.NotContains(t, buf.String(), "other debug")
+}
+
+type recordingHook struct {
+	l        sync.Mutex
+	messages []string
+}
+
+func (h *recordingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *recordingHook) Fire(entry *logrus.Entry) error {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+func TestComponentLevels_hooks(t *testing.T) {
+	defer SetComponentLevels(ComponentLevels())
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+	hooks := logrus.StandardLogger().Hooks
+	defer logrus.StandardLogger().ReplaceHooks(hooks)
+
+	logrus.StandardLogger().SetOutput(&bytes.Buffer{})
+	hook := &recordingHook{}
+	logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	logrus.AddHook(hook)
+	SetLevels(logrus.InfoLevel, map[string]logrus.Level{"verbose": logrus.DebugLevel, "quiet": logrus.WarnLevel})
+
+	ctx := context.Background()
+	New("verbose")(ctx).Debug("verbose debug")
+	New("quiet")(ctx).Info("quiet info")
+	New("quiet")(ctx).Error("quiet error")
+	New("other")(ctx).Debug("other debug")
+	New("other")(ctx).WithField("key", "value").Info("other info")
+
+	// The hooks of the standard logger only receive the entries enabled for their component
+	assert.Equal(t, []string{"verbose debug", "quiet error", "other info"}, hook.messages)
+	assert.Equal(t, logrus.InfoLevel, DefaultLevel())
+}
@@ -16,12 +16,15 @@ var GlobalFields = logrus.Fields{}
 
 type Logger func(Valuer, ...error) *logrus.Entry
 
+// New creates a Logger tagging entries with the component name.
+// Entries are logged with the level set by SetComponentLevel for the component, or the DefaultLevel. The entries
+// below that level are dropped before reaching the hooks of the standard logger.
 func New(name string) Logger {
 	return func(ctx Valuer, err ...error) *logrus.Entry {
 		if len(err) == 1 && err[0] == nil {
 			err = nil
 		}
-		return ContextLog(ctx, err, nil).WithField("component", name)
+		return ContextLog(ctx, err, componentEntry(name)).WithField("component", name)
 	}
 }
 
@@ -34,28 +37,43 @@ func ContextLog(ctx Valuer, err []error, entry *logrus.Entry) *logrus.Entry {
 	if entry == nil {
 		entry = logrus.NewEntry(logrus.StandardLogger())
 	}
-	entry = entry.WithFields(GlobalFields)
+	entry = entry.WithFields(ContextFields(ctx, err...))
+
+	if ctx, ok := ctx.(context.Context); ok && ctx != nil {
+		entry = entry.WithContext(ctx)
+	}
+	return entry
+}
+
+// ContextFields returns the fields to be logged for a Context and errors:
+// GlobalFields, the fields attached to the Context, then the errors with their causes and their LogFields.
+//
+// It is shared by ContextLog and the Sink-based loggers, such that fields are the same whatever the backend.
+func ContextFields(ctx Valuer, err ...error) logrus.Fields {
+	fields := logrus.Fields{}
+	for k, v := range GlobalFields {
+		fields[k] = v
+	}
 
 	if ctx != nil {
-		entry = entry.WithFields(GetLoggableValues(ctx))
-		if ctx, ok := ctx.(context.Context); ok {
-			entry = entry.WithContext(ctx)
+		for k, v := range GetLoggableValues(ctx) {
+			fields[k] = v
 		}
 	}
 
 	if len(err) != 0 {
 		err0 := err[0]
-		entry = entry.WithField("error", err0)
+		fields["error"] = err0
 
 		if _, ok := err0.(causer); ok {
-			entry = entry.WithField("cause", errors.Cause(err0))
+			fields["cause"] = errors.Cause(err0)
 		}
 
 		for i, errX := range err[1:] {
-			entry = entry.WithField(fmt.Sprintf("error%d", i+1), errX)
+			fields[fmt.Sprintf("error%d", i+1)] = errX
 
 			if _, ok := errX.(causer); ok {
-				entry = entry.WithField(fmt.Sprintf("cause%d", i+1), errors.Cause(errX))
+				fields[fmt.Sprintf("cause%d", i+1)] = errors.Cause(errX)
 			}
 		}
 
@@ -63,11 +81,13 @@ func ContextLog(ctx Valuer, err []error, entry *logrus.Entry) *logrus.Entry {
 		// Do not recurse in error causes, the error itself should merge its causes' fields if desired.
 		var loggable loggableError
 		if errors.As(err0, &loggable) {
-			entry = entry.WithFields(loggable.LogFields())
+			for k, v := range loggable.LogFields() {
+				fields[k] = v
+			}
 		}
 	}
 
-	return entry
+	return fields
 }
 
 // LogIfError makes it less verbose to defer a Close() call while
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Shopify/goose/redact"
+)
+
+// RedactingFormatter wraps a Formatter, filtering sensitive data out of the entry fields with redact.Value:
+// fields with a sensitive key, sensitive keys of nested maps and structs, and "KEY=VALUE" strings of slices,
+// such as the environment of shell commands.
+//
+//	logrus.SetFormatter(&logger.RedactingFormatter{Formatter: logrus.StandardLogger().Formatter})
+//
+// Hooks are unaffected, since logrus calls them before formatting.
+type RedactingFormatter struct {
+	Formatter logrus.Formatter
+}
+
+func (f *RedactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	redacted, ok := redact.Value(map[string]interface{}(entry.Data)).(map[string]interface{})
+	if !ok {
+		return f.Formatter.Format(entry)
+	}
+
+	// Copy the entry rather than modifying the fields, which may be shared with other entries.
+	e := *entry
+	e.Data = redacted
+	return f.Formatter.Format(&e)
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactingFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logrusLogger := logrus.New()
+	logrusLogger.Out = buf
+	logrusLogger.Formatter = &RedactingFormatter{
+		Formatter: &logrus.JSONFormatter{DisableTimestamp: true},
+	}
+
+	ctx := WithField(context.Background(), "apiToken", "abc")
+	ctx = WithField(ctx, "request", map[string]interface{}{"headers": map[string]string{"Cookie": "session=1"}})
+
+	entry := ContextLog(ctx, nil, logrus.NewEntry(logrusLogger)).WithFields(logrus.Fields{
+		"cmdArgs": []string{"deploy", "--secret=abc"},
+		"cmdEnv":  []string{"HOME=/root", "GITHUB_TOKEN=abc"},
+	})
+	entry.Info("running")
+
+	assert.JSONEq(t, `{
+		"level": "info",
+		"msg": "running",
+		"apiToken": "[FILTERED]",
+		"request": {"headers": {"Cookie": "[FILTERED]"}},
+		"cmdArgs": ["deploy", "--secret=[FILTERED]"],
+		"cmdEnv": ["HOME=/root", "GITHUB_TOKEN=[FILTERED]"]
+	}`, buf.String())
+
+	// The entry itself isn't modified
+	assert.Equal(t, "abc", entry.Data["apiToken"])
+	assert.Equal(t, []string{"HOME=/root", "GITHUB_TOKEN=abc"}, entry.Data["cmdEnv"])
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/tomb.v2"
+)
+
+// DroppedEntriesField is the field of the summary lines logged by a Sampler, holding the number of dropped entries.
+// Entries with this field are never sampled.
+const DroppedEntriesField = "droppedEntries"
+
+// SamplingConfig configures a Sampler.
+type SamplingConfig struct {
+	// Interval is the sampling window, as well as the period of the summary lines. Defaults to 1s.
+	Interval time.Duration
+	// First is the number of entries logged per window, for each component, message and level.
+	First int
+	// Thereafter is the rate of entries logged once First is reached: 1 in Thereafter is logged.
+	// 0 drops all the entries after First.
+	Thereafter int
+	// OnDropped is called when reporting the entries dropped for a component, message and level, if set.
+	// It is meant to increment a counter, see metrics.CountDroppedLogEntries.
+	OnDropped func(component string, level logrus.Level, message string, dropped int)
+}
+
+// Sampler limits the number of log entries written per component, message and level.
+//
+// Install it with Formatter, and run it as a genmain.Component to log a summary line every interval for the keys
+// which had dropped entries. Hooks are unaffected, since logrus calls them before formatting.
+type Sampler struct {
+	tomb   tomb.Tomb
+	config SamplingConfig
+
+	l    sync.Mutex
+	keys map[samplingKey]*samplingCounter
+}
+
+type samplingKey struct {
+	component string
+	level     logrus.Level
+	message   string
+}
+
+type samplingCounter struct {
+	windowStart time.Time
+	seen        int
+	dropped     int
+}
+
+// NewSampler creates a new Sampler.
+func NewSampler(config SamplingConfig) *Sampler {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &Sampler{
+		config: config,
+		keys:   map[samplingKey]*samplingCounter{},
+	}
+}
+
+// Formatter wraps a Formatter, such that the entries dropped by the Sampler are not written:
+//
+//	logrus.SetFormatter(sampler.Formatter(logrus.StandardLogger().Formatter))
+func (s *Sampler) Formatter(inner logrus.Formatter) logrus.Formatter {
+	return &samplingFormatter{sampler: s, inner: inner}
+}
+
+type samplingFormatter struct {
+	sampler *Sampler
+	inner   logrus.Formatter
+}
+
+func (f *samplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.sampler.Keep(entry) {
+		return nil, nil
+	}
+	return f.inner.Format(entry)
+}
+
+// Keep returns whether the entry should be written, and counts it as dropped otherwise.
+func (s *Sampler) Keep(entry *logrus.Entry) bool {
+	if _, ok := entry.Data[DroppedEntriesField]; ok {
+		return true
+	}
+
+	component, _ := entry.Data["component"].(string)
+	key := samplingKey{component: component, level: entry.Level, message: entry.Message}
+	now := time.Now()
+
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	c, ok := s.keys[key]
+	if !ok {
+		c = &samplingCounter{windowStart: now}
+		s.keys[key] = c
+	} else if now.Sub(c.windowStart) >= s.config.Interval {
+		c.windowStart = now
+		c.seen = 0
+	}
+
+	c.seen++
+	if c.seen <= s.config.First {
+		return true
+	}
+	if s.config.Thereafter > 0 && (c.seen-s.config.First)%s.config.Thereafter == 0 {
+		return true
+	}
+	c.dropped++
+	return false
+}
+
+func (s *Sampler) Tomb() *tomb.Tomb {
+	return &s.tomb
+}
+
+// Run reports the dropped entries every interval, until the Sampler is killed.
+func (s *Sampler) Run() error {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.tomb.Dying():
+			s.Report()
+			return nil
+		case <-ticker.C:
+			s.Report()
+		}
+	}
+}
+
+// Report logs a summary line for every component, message and level which had dropped entries since the last
+// report, and calls OnDropped. Idle keys are forgotten.
+func (s *Sampler) Report() {
+	now := time.Now()
+	var reports []samplingReport
+
+	s.l.Lock()
+	for key, c := range s.keys {
+		if c.dropped > 0 {
+			reports = append(reports, samplingReport{samplingKey: key, dropped: c.dropped})
+			c.dropped = 0
+		} else if now.Sub(c.windowStart) >= s.config.Interval {
+			delete(s.keys, key)
+		}
+	}
+	s.l.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if a.component != b.component {
+			return a.component < b.component
+		}
+		if a.message != b.message {
+			return a.message < b.message
+		}
+		return a.level < b.level
+	})
+
+	for _, r := range reports {
+		log(nil, nil).
+			WithField("sampledComponent", r.component).
+			WithField("sampledLevel", r.level.String()).
+			WithField("sampledMessage", r.message).
+			WithField(DroppedEntriesField, r.dropped).
+			Info("dropped sampled log entries")
+
+		if s.config.OnDropped != nil {
+			s.config.OnDropped(r.component, r.level, r.message, r.dropped)
+		}
+	}
+}
+
+type samplingReport struct {
+	samplingKey
+	dropped int
+}
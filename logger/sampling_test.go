@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler(t *testing.T) {
+	type dropReport struct {
+		component string
+		level     logrus.Level
+		message   string
+		dropped   int
+	}
+	var reports []dropReport
+
+	sampler := NewSampler(SamplingConfig{
+		Interval:   time.Hour,
+		First:      2,
+		Thereafter: 3,
+		OnDropped: func(component string, level logrus.Level, message string, dropped int) {
+			reports = append(reports, dropReport{component, level, message, dropped})
+		},
+	})
+
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	formatter := logrus.StandardLogger().Formatter
+	defer logrus.SetFormatter(formatter)
+
+	buf := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(buf)
+	logrus.SetFormatter(sampler.Formatter(&logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}))
+
+	requests := New("requests")
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		requests(ctx).WithField("i", i).Info("http request")
+	}
+	requests(ctx).Warn("http request")
+	New("other")(ctx).Info("http request")
+
+	// First 2, then 1 in 3
+	assert.Equal(t, []string{
+		"level=info msg=\"http request\" component=requests i=0",
+		"level=info msg=\"http request\" component=requests i=1",
+		"level=info msg=\"http request\" component=requests i=4",
+		"level=info msg=\"http request\" component=requests i=7",
+		"level=warning msg=\"http request\" component=requests",
+		"level=info msg=\"http request\" component=other",
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	buf.Reset()
+	sampler.Report()
+	assert.Equal(t, "level=info msg=\"dropped sampled log entries\" component=logger droppedEntries=6 "+
+		"sampledComponent=requests sampledLevel=info sampledMessage=\"http request\"\n", buf.String())
+	assert.Equal(t, []dropReport{{"requests", logrus.InfoLevel, "http request", 6}}, reports)
+
+	// Nothing new to report
+	buf.Reset()
+	sampler.Report()
+	assert.Empty(t, buf.String())
+	assert.Len(t, reports, 1)
+}
+
+func TestSampler_interval(t *testing.T) {
+	sampler := NewSampler(SamplingConfig{Interval: 10 * time.Millisecond, First: 1})
+	entry := logrus.NewEntry(logrus.New()).WithField("component", "test")
+	entry.Message = "message"
+
+	assert.True(t, sampler.Keep(entry))
+	assert.False(t, sampler.Keep(entry))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, sampler.Keep(entry), "a new window starts")
+	assert.False(t, sampler.Keep(entry))
+}
+
+func TestSampler_run(t *testing.T) {
+	var dropped int
+	sampler := NewSampler(SamplingConfig{
+		Interval: time.Hour,
+		OnDropped: func(_ string, _ logrus.Level, _ string, n int) {
+			dropped += n
+		},
+	})
+	entry := logrus.NewEntry(logrus.New())
+	assert.False(t, sampler.Keep(entry))
+
+	sampler.Tomb().Go(sampler.Run)
+	sampler.Tomb().Kill(nil)
+	require.NoError(t, sampler.Tomb().Wait())
+	assert.Equal(t, 1, dropped, "reported when killed")
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Level is the severity of a log entry written to a Sink.
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warning"
+	case ErrorLevel:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// Fields are the fields of a log entry written to a Sink.
+// They contain the values as they were attached, notably errors are not converted to strings.
+type Fields map[string]interface{}
+
+// Sink writes log entries to a logging library, such as logrus (NewLogrusSink), log/slog (NewSlogSink)
+// or zap (zapsink.New). Implementations don't need to import logrus.
+//
+// Only SinkLogger and ContextEntry write to a Sink. Logger, ContextLog and ContextFields still return logrus types,
+// and the logger package itself still depends on logrus.
+type Sink interface {
+	Log(ctx context.Context, level Level, msg string, fields Fields)
+}
+
+// SinkLogger is the same as Logger, but writes to a Sink instead of returning a *logrus.Entry.
+type SinkLogger func(Valuer, ...error) *Entry
+
+// NewSinkLogger creates a SinkLogger writing to the Sink, with the same fields as New:
+// the component name, and the fields returned by ContextFields.
+func NewSinkLogger(name string, sink Sink) SinkLogger {
+	return func(ctx Valuer, err ...error) *Entry {
+		if len(err) == 1 && err[0] == nil {
+			err = nil
+		}
+		return ContextEntry(ctx, err, sink).WithField("component", name)
+	}
+}
+
+// ContextEntry is the same as ContextLog, but creates an Entry writing to the Sink.
+func ContextEntry(ctx Valuer, err []error, sink Sink) *Entry {
+	e := &Entry{
+		sink:   sink,
+		Fields: Fields(ContextFields(ctx, err...)),
+	}
+	if ctx, ok := ctx.(context.Context); ok && ctx != nil {
+		e.ctx = ctx
+	}
+	return e
+}
+
+// Entry is a log entry being built, to be written to a Sink.
+type Entry struct {
+	sink   Sink
+	ctx    context.Context
+	Fields Fields
+}
+
+// WithField returns a copy of the Entry with an additional field.
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+// WithFields returns a copy of the Entry with additional fields.
+func (e *Entry) WithFields(fields Fields) *Entry {
+	merged := make(Fields, len(e.Fields)+len(fields))
+	for k, v := range e.Fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Entry{sink: e.sink, ctx: e.ctx, Fields: merged}
+}
+
+// Log writes the Entry to the Sink.
+func (e *Entry) Log(level Level, msg string) {
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e.sink.Log(ctx, level, msg, e.Fields)
+}
+
+func (e *Entry) Debug(args ...interface{}) {
+	e.Log(DebugLevel, fmt.Sprint(args...))
+}
+
+func (e *Entry) Debugf(format string, args ...interface{}) {
+	e.Log(DebugLevel, fmt.Sprintf(format, args...))
+}
+
+func (e *Entry) Info(args ...interface{}) {
+	e.Log(InfoLevel, fmt.Sprint(args...))
+}
+
+func (e *Entry) Infof(format string, args ...interface{}) {
+	e.Log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (e *Entry) Warn(args ...interface{}) {
+	e.Log(WarnLevel, fmt.Sprint(args...))
+}
+
+func (e *Entry) Warnf(format string, args ...interface{}) {
+	e.Log(WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (e *Entry) Error(args ...interface{}) {
+	e.Log(ErrorLevel, fmt.Sprint(args...))
+}
+
+func (e *Entry) Errorf(format string, args ...interface{}) {
+	e.Log(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// NewLogrusSink creates a Sink writing to a logrus Logger. The standard logger is used if nil.
+func NewLogrusSink(l *logrus.Logger) Sink {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return &logrusSink{logger: l}
+}
+
+type logrusSink struct {
+	logger *logrus.Logger
+}
+
+func (s *logrusSink) Log(ctx context.Context, level Level, msg string, fields Fields) {
+	logrus.NewEntry(s.logger).WithContext(ctx).WithFields(logrus.Fields(fields)).Log(logrusLevel(level), msg)
+}
+
+func logrusLevel(level Level) logrus.Level {
+	switch level {
+	case DebugLevel:
+		return logrus.DebugLevel
+	case InfoLevel:
+		return logrus.InfoLevel
+	case WarnLevel:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
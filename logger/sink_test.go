@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	ctx    context.Context
+	level  Level
+	msg    string
+	fields Fields
+}
+
+func (s *recordingSink) Log(ctx context.Context, level Level, msg string, fields Fields) {
+	s.ctx, s.level, s.msg, s.fields = ctx, level, msg, fields
+}
+
+func TestSinkLogger(t *testing.T) {
+	origGlobal := GlobalFields
+	defer func() { GlobalFields = origGlobal }()
+	GlobalFields = logrus.Fields{"global": "yes"}
+
+	cause := &logFieldsErr{"bad stuff", logrus.Fields{"foo": "bar"}}
+	err := errors.Wrap(cause, "wrapped")
+	ctx := WithLoggable(context.Background(), &testLoggable{keys: logrus.Fields{"loggable": "ok"}})
+	ctx = WithField(ctx, "a", "b")
+
+	sink := &recordingSink{}
+	log := NewSinkLogger("foo", sink)
+	log(ctx, err).WithField("c", "d").Warnf("failed %d times", 2)
+
+	assert.Equal(t, WarnLevel, sink.level)
+	assert.Equal(t, "failed 2 times", sink.msg)
+	assert.Equal(t, ctx, sink.ctx)
+
+	// Same fields as the logrus Logger
+	expected := New("foo")(ctx, err).WithField("c", "d").Data
+	assert.Equal(t, Fields(expected), sink.fields)
+	assert.Equal(t, Fields{
+		"global":    "yes",
+		"component": "foo",
+		"loggable":  "ok",
+		"a":         "b",
+		"c":         "d",
+		"foo":       "bar",
+		"error":     err,
+		"cause":     cause,
+	}, sink.fields)
+
+	// nil errors and contexts are ignored
+	log(nil, nil).Info("no context")
+	assert.Equal(t, context.Background(), sink.ctx)
+	assert.Equal(t, Fields{"global": "yes", "component": "foo"}, sink.fields)
+}
+
+func TestLogrusSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logrusLogger := logrus.New()
+	logrusLogger.Out = buf
+	logrusLogger.Formatter = &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}
+
+	log := NewSinkLogger("foo", NewLogrusSink(logrusLogger))
+	log(WithField(context.Background(), "a", "b"), errors.New("bad")).Error("failed")
+	log(context.Background()).Debug("filtered out")
+
+	assert.Equal(t, "level=error msg=failed a=b component=foo error=bad\n", buf.String())
+}
@@ -0,0 +1,199 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NewSlogSink creates a Sink writing to a log/slog Logger. The default logger is used if nil.
+// Fields are converted to attributes sorted by key.
+func NewSlogSink(l *slog.Logger) Sink {
+	return &slogSink{logger: l}
+}
+
+type slogSink struct {
+	logger *slog.Logger
+}
+
+func (s *slogSink) Log(ctx context.Context, level Level, msg string, fields Fields) {
+	l := s.logger
+	if l == nil {
+		l = slog.Default()
+	}
+	l.LogAttrs(ctx, slogLevel(level), msg, fieldsToAttrs(fields)...)
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func fieldsToAttrs(fields Fields) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for k, v := range fields {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+	return attrs
+}
+
+// NewSlogHandler creates a slog.Handler adding the goose fields to the records before passing them to inner:
+// GlobalFields and the fields attached to the Context, as returned by ContextFields.
+// The fields are added at the top level, outside of the groups of the Logger, and the attributes of the record or
+// of the Logger take precedence over them.
+//
+// Error attributes are expanded like ContextLog does: the cause of an "error" attribute is added as "cause",
+// the cause of an "error1" attribute as "cause1", and so on, and the LogFields of the first error are added.
+// Only the attributes outside of groups are expanded.
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(slog.NewJSONHandler(os.Stderr, nil))))
+//	slog.InfoContext(ctx, "request handled") // Includes the request UUID attached by srvutil.BuildContext
+func NewSlogHandler(inner slog.Handler) slog.Handler {
+	return &slogHandler{inner: inner, applied: inner}
+}
+
+type slogHandler struct {
+	inner slog.Handler
+	// ops are the WithAttrs and WithGroup calls. When a group is open, Handle applies them to inner after adding
+	// the fields, such that the fields are at the top level.
+	ops []slogHandlerOp
+	// applied is inner with ops applied, used when no group is open or there are no fields to add.
+	applied slog.Handler
+}
+
+// slogHandlerOp is either a WithGroup or a WithAttrs call.
+type slogHandlerOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	topKeys := map[string]struct{}{}
+	var errAttrs []slog.Attr
+	visit := func(a slog.Attr) bool {
+		topKeys[a.Key] = struct{}{}
+		if _, ok := a.Value.Any().(error); ok && a.Value.Kind() == slog.KindAny {
+			errAttrs = append(errAttrs, a)
+		}
+		return true
+	}
+
+	// Only the attributes outside of groups may clash with the fields.
+	grouped := false
+	for _, op := range h.ops {
+		if op.group != "" {
+			grouped = true
+			break
+		}
+		for _, a := range op.attrs {
+			visit(a)
+		}
+	}
+	if !grouped {
+		r.Attrs(visit)
+	}
+
+	fields := Fields(ContextFields(ctx))
+	for i, a := range errAttrs {
+		err := a.Value.Any().(error)
+		if _, ok := err.(causer); ok {
+			fields[causeKey(a.Key)] = errors.Cause(err)
+		}
+
+		// Same as ContextLog, only the first error's fields are added.
+		var loggable loggableError
+		if i == 0 && errors.As(err, &loggable) {
+			for k, v := range loggable.LogFields() {
+				fields[k] = v
+			}
+		}
+	}
+
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, a := range fieldsToAttrs(fields) {
+		if _, ok := topKeys[a.Key]; !ok {
+			attrs = append(attrs, a)
+		}
+	}
+	if len(attrs) == 0 {
+		return h.applied.Handle(ctx, r)
+	}
+
+	if !grouped {
+		// The record attributes are at the top level, the fields can be added to them.
+		withFields := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		withFields.AddAttrs(attrs...)
+		r.Attrs(func(a slog.Attr) bool {
+			withFields.AddAttrs(a)
+			return true
+		})
+		return h.applied.Handle(ctx, withFields)
+	}
+
+	// The record attributes are in a group, the fields are added to inner before applying the ops instead.
+	inner := h.inner.WithAttrs(attrs)
+	for _, op := range h.ops {
+		inner = op.apply(inner)
+	}
+	return inner.Handle(ctx, r)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(slogHandlerOp{attrs: attrs})
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(slogHandlerOp{group: name})
+}
+
+func (h *slogHandler) with(op slogHandlerOp) *slogHandler {
+	ops := make([]slogHandlerOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &slogHandler{
+		inner:   h.inner,
+		ops:     append(ops, op),
+		applied: op.apply(h.applied),
+	}
+}
+
+func (op slogHandlerOp) apply(h slog.Handler) slog.Handler {
+	if op.group != "" {
+		return h.WithGroup(op.group)
+	}
+	return h.WithAttrs(op.attrs)
+}
+
+// causeKey returns the key of the cause of an error attribute: "error" becomes "cause", "error1" becomes "cause1",
+// and any other key gets a "Cause" suffix.
+func causeKey(errKey string) string {
+	if suffix, ok := strings.CutPrefix(errKey, "error"); ok {
+		return "cause" + suffix
+	}
+	return errKey + "Cause"
+}
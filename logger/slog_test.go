@@ -0,0 +1,179 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slogLogger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	log := NewSinkLogger("foo", NewSlogSink(slogLogger))
+	log(WithField(context.Background(), "a", "b"), errors.New("bad")).Warn("failed")
+	log(context.Background()).Debug("filtered out")
+
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{
+		"level":     "WARN",
+		"msg":       "failed",
+		"a":         "b",
+		"component": "foo",
+		"error":     "bad",
+	}, entry)
+}
+
+func TestSlogHandler(t *testing.T) {
+	origGlobal := GlobalFields
+	defer func() { GlobalFields = origGlobal }()
+	GlobalFields = logrus.Fields{"global": "yes"}
+
+	buf := &bytes.Buffer{}
+	handler := NewSlogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	log := slog.New(handler).With("component", "foo")
+
+	ctx, requestID := WithUUID(context.Background())
+	ctx = WithField(ctx, "route", "/hello")
+
+	cause := &logFieldsErr{"bad stuff", logrus.Fields{"foo": "bar"}}
+	log.ErrorContext(ctx, "failed", "error", errors.Wrap(cause, "wrapped"), "route", "/override")
+
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{
+		"level":     "ERROR",
+		"msg":       "failed",
+		"component": "foo",
+		"global":    "yes",
+		"uuid":      requestID,
+		"route":     "/override",
+		"error":     "wrapped: bad stuff",
+		"cause":     "bad stuff",
+		"foo":       "bar",
+	}, entry)
+
+	// Without a context
+	buf.Reset()
+	slog.New(handler).Warn("no context")
+	entry = map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{"level": "WARN", "msg": "no context", "global": "yes"}, entry)
+}
+
+func TestSlogHandler_groups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := NewSlogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	log := slog.New(handler).With("component", "foo").WithGroup("request").With("method", "GET")
+
+	ctx := WithField(context.Background(), "route", "/hello")
+	log.InfoContext(ctx, "handled", "route", "/nested")
+
+	// The context fields are at the top level, and don't clash with the grouped attributes
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{
+		"level":     "INFO",
+		"msg":       "handled",
+		"component": "foo",
+		"route":     "/hello",
+		"request": map[string]interface{}{
+			"method": "GET",
+			"route":  "/nested",
+		},
+	}, entry)
+}
+
+func TestSlogHandler_withAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := NewSlogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	cause := &logFieldsErr{"bad stuff", logrus.Fields{"foo": "bar"}}
+	log := slog.New(handler).With("error", errors.Wrap(cause, "wrapped"), "route", "/override")
+
+	ctx := WithField(context.Background(), "route", "/hello")
+	log.ErrorContext(ctx, "failed")
+
+	// Attributes of the Logger are expanded, and take precedence over the context fields
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{
+		"level": "ERROR",
+		"msg":   "failed",
+		"route": "/override",
+		"error": "wrapped: bad stuff",
+		"cause": "bad stuff",
+		"foo":   "bar",
+	}, entry)
+}
+
+// countingHandler counts the calls to WithAttrs.
+type countingHandler struct {
+	slog.Handler
+	withAttrs *int
+}
+
+func (h countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	*h.withAttrs++
+	return countingHandler{Handler: h.Handler.WithAttrs(attrs), withAttrs: h.withAttrs}
+}
+
+func TestSlogHandler_recordAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	withAttrs := 0
+	handler := NewSlogHandler(countingHandler{Handler: slog.NewJSONHandler(buf, nil), withAttrs: &withAttrs})
+	log := slog.New(handler).With("component", "foo")
+	require.Equal(t, 1, withAttrs)
+
+	ctx := WithField(context.Background(), "route", "/hello")
+	log.InfoContext(ctx, "first")
+	log.InfoContext(ctx, "second")
+
+	// The fields are added to the records, without deriving a handler for each of them
+	assert.Equal(t, 1, withAttrs)
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`"component":"foo","route":"/hello"`)))
+}
+
+func TestCauseKey(t *testing.T) {
+	assert.Equal(t, "cause", causeKey("error"))
+	assert.Equal(t, "cause2", causeKey("error2"))
+	assert.Equal(t, "lastErrCause", causeKey("lastErr"))
+}
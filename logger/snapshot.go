@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Snapshot copies the fields attached to the Context, such that they can be restored with Restore on a Context
+// which isn't derived from it, for example by a worker pool or after being serialized into a job queue.
+//
+// Values are converted such that the snapshot is unchanged by a JSON round-trip: errors and fmt.Stringers are
+// formatted as strings, and other values are converted to their JSON representation (numbers become float64).
+func Snapshot(ctx Valuer) map[string]interface{} {
+	fields := GetLoggableValues(ctx)
+	snapshot := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		snapshot[k] = snapshotValue(v)
+	}
+	return snapshot
+}
+
+// Restore attaches the fields of a Snapshot to the Context.
+func Restore(ctx context.Context, snapshot map[string]interface{}) context.Context {
+	return WithFields(ctx, snapshot)
+}
+
+func snapshotValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, string, bool, float64:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return fmt.Sprint(v)
+	}
+	return out
+}
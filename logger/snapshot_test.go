@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := WithFields(context.Background(), map[string]interface{}{
+		"string":   "value",
+		"int":      3,
+		"bool":     true,
+		"nil":      nil,
+		"error":    errors.New("failed"),
+		"duration": time.Second,
+		"struct":   struct{ Name string }{Name: "name"},
+		"slice":    []string{"a", "b"},
+		"func":     func() {},
+	})
+
+	snapshot := Snapshot(ctx)
+	assert.Equal(t, map[string]interface{}{
+		"string":   "value",
+		"int":      float64(3),
+		"bool":     true,
+		"nil":      nil,
+		"error":    "failed",
+		"duration": "1s",
+		"struct":   map[string]interface{}{"Name": "name"},
+		"slice":    []interface{}{"a", "b"},
+		"func":     snapshot["func"].(string), // Unserializable, formatted with fmt.Sprint
+	}, snapshot)
+
+	b, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, snapshot, decoded)
+
+	restored := Restore(context.Background(), decoded)
+	assert.Equal(t, snapshot, Snapshot(restored))
+}
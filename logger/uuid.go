@@ -2,28 +2,120 @@ package logger
 
 import (
 	"context"
-
-	uuid "github.com/nu7hatch/gouuid"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
 )
 
 const UUIDKey = "uuid"
 
+// Keys of the log fields identifying the trace and the span of a Context, as set by the trace package.
+const (
+	TraceIDKey = "traceID"
+	SpanIDKey  = "spanID"
+)
+
 var log = New("logger")
 
+// IDGenerator generates the request IDs attached by WithUUID, such as NewUUIDv4, NewUUIDv7 or NewULID.
+type IDGenerator func() string
+
+var idGenerator atomic.Value // IDGenerator
+
+func init() {
+	idGenerator.Store(IDGenerator(NewUUIDv4))
+}
+
+// SetIDGenerator sets the IDGenerator used by WithUUID and NewID. Defaults to NewUUIDv4.
+// It should be called once at application startup.
+func SetIDGenerator(g IDGenerator) {
+	if g == nil {
+		g = NewUUIDv4
+	}
+	idGenerator.Store(g)
+}
+
+// NewID generates an ID with the IDGenerator set by SetIDGenerator.
+func NewID() string {
+	return idGenerator.Load().(IDGenerator)()
+}
+
 func WithUUID(ctx context.Context) (context.Context, string) {
 	if id := GetLoggableValue(ctx, UUIDKey); id != nil {
 		return ctx, id.(string)
 	}
 
-	requestID := newUUID()
+	requestID := NewID()
 	return WithField(ctx, UUIDKey, requestID), requestID
 }
 
-func newUUID() string {
-	u, err := uuid.NewV4()
-	if err != nil {
-		log(nil, err).Error("unable to generate uuid v4")
-		return "00000000-0000-0000-0000-000000000000"
+// NewUUIDv4 generates a random UUID, as defined by RFC 9562.
+func NewUUIDv4() string {
+	var u [16]byte
+	randomBytes(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40 // Version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant 10
+	return formatUUID(u)
+}
+
+// NewUUIDv7 generates a UUID starting with the current Unix timestamp in milliseconds, as defined by RFC 9562.
+// Unlike version 4, the IDs sort by creation time.
+func NewUUIDv7() string {
+	var u [16]byte
+	randomBytes(u[6:])
+	putUint48(u[:6], uint64(time.Now().UnixMilli()))
+	u[6] = (u[6] & 0x0f) | 0x70 // Version 7
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant 10
+	return formatUUID(u)
+}
+
+// crockfordAlphabet is the Base32 alphabet of ULIDs, excluding I, L, O and U.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID generates a ULID (https://github.com/ulid/spec): a 48-bit Unix timestamp in milliseconds followed by
+// 80 random bits, encoded as 26 characters of Crockford's Base32. The IDs sort by creation time.
+func NewULID() string {
+	var u [16]byte
+	randomBytes(u[6:])
+	putUint48(u[:6], uint64(time.Now().UnixMilli()))
+
+	// Encode the 128 bits as 26 groups of 5 bits, starting from the least significant.
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
 	}
-	return u.String()
+	return string(out[:])
+}
+
+func putUint48(b []byte, v uint64) {
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		log(nil, err).Error("unable to generate random id")
+	}
+}
+
+func formatUUID(u [16]byte) string {
+	var out [36]byte
+	hex.Encode(out[0:8], u[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], u[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], u[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], u[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], u[10:])
+	return string(out[:])
 }
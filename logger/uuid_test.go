@@ -2,9 +2,13 @@ package logger
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithUUID(t *testing.T) {
@@ -19,3 +23,52 @@ func TestWithUUID(t *testing.T) {
 	assert.Equal(t, ctx, ctx2)
 	assert.Equal(t, id, id2)
 }
+
+func TestSetIDGenerator(t *testing.T) {
+	defer SetIDGenerator(nil)
+
+	SetIDGenerator(func() string { return "custom" })
+	_, id := WithUUID(context.Background())
+	assert.Equal(t, "custom", id)
+
+	SetIDGenerator(nil)
+	assert.Regexp(t, uuidV4Pattern, NewID())
+}
+
+var (
+	uuidV4Pattern = `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
+	uuidV7Pattern = `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
+	ulidPattern   = `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`
+)
+
+func TestNewUUIDv4(t *testing.T) {
+	id := NewUUIDv4()
+	assert.Regexp(t, uuidV4Pattern, id)
+	assert.NotEqual(t, id, NewUUIDv4())
+}
+
+func TestNewUUIDv7(t *testing.T) {
+	id := NewUUIDv7()
+	assert.Regexp(t, uuidV7Pattern, id)
+
+	ms, err := strconv.ParseInt(strings.ReplaceAll(id[:13], "-", ""), 16, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.UnixMilli(ms), time.Second)
+
+	time.Sleep(2 * time.Millisecond)
+	assert.Less(t, id, NewUUIDv7())
+}
+
+func TestNewULID(t *testing.T) {
+	id := NewULID()
+	assert.Regexp(t, ulidPattern, id)
+
+	var ms int64
+	for _, c := range id[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockfordAlphabet, c))
+	}
+	assert.WithinDuration(t, time.Now(), time.UnixMilli(ms), time.Second)
+
+	time.Sleep(2 * time.Millisecond)
+	assert.Less(t, id, NewULID())
+}
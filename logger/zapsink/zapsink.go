@@ -0,0 +1,52 @@
+// Package zapsink provides a logger.Sink writing to a zap Logger.
+// It is a separate module, such that importing goose doesn't pull in zap.
+package zapsink
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/Shopify/goose/logger"
+)
+
+// New creates a logger.Sink writing to a zap Logger.
+// Fields are converted to zap fields sorted by key.
+func New(l *zap.Logger) logger.Sink {
+	return &sink{logger: l}
+}
+
+type sink struct {
+	logger *zap.Logger
+}
+
+func (s *sink) Log(_ context.Context, level logger.Level, msg string, fields logger.Fields) {
+	ce := s.logger.Check(zapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+
+	zapFields := make([]zap.Field, 0, len(fields))
+	for k, v := range fields {
+		zapFields = append(zapFields, zap.Any(k, v))
+	}
+	sort.Slice(zapFields, func(i, j int) bool {
+		return zapFields[i].Key < zapFields[j].Key
+	})
+	ce.Write(zapFields...)
+}
+
+func zapLevel(level logger.Level) zapcore.Level {
+	switch level {
+	case logger.DebugLevel:
+		return zapcore.DebugLevel
+	case logger.InfoLevel:
+		return zapcore.InfoLevel
+	case logger.WarnLevel:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
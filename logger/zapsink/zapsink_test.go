@@ -0,0 +1,38 @@
+package zapsink_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/Shopify/goose/logger"
+	"github.com/Shopify/goose/logger/zapsink"
+)
+
+func TestSink(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := logger.NewSinkLogger("test", zapsink.New(zap.New(core)))
+
+	ctx := logger.WithField(context.Background(), "request", "abc")
+	err := errors.New("failed")
+
+	log(ctx, err).WithField("attempt", 2).Warn("retrying")
+	log(ctx).Debug("filtered out")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, "retrying", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"attempt":   int64(2),
+		"component": "test",
+		"error":     "failed",
+		"request":   "abc",
+	}, entries[0].ContextMap())
+}
@@ -1,16 +1,56 @@
 package metrics
 
 import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
 	"github.com/Shopify/goose/statsd"
 )
 
 // Note that statsd's default backend is the nullBackend, which doesn't do anything.
 // For these metrics to work, use statsd.SetBackend.
 var (
-	GenMainRun      = &statsd.Timer{Name: "genmain.run"}
-	GenMainShutdown = &statsd.Timer{Name: "genmain.shutdown"}
+	GenMainRun = statsd.DefaultRegistry.Timer(statsd.Definition{
+		Name:        "genmain.run",
+		Description: "Time spent running all the components of a genmain.Main, until they are shut down.",
+	})
+	GenMainShutdown = statsd.DefaultRegistry.Timer(statsd.Definition{
+		Name:        "genmain.shutdown",
+		Description: "Time taken by a genmain component to shut down, since the shutdown was requested.",
+		TagKeys:     []string{"success", "deadline", "mainComponent"},
+	})
+
+	HTTPRequest = statsd.DefaultRegistry.Timer(statsd.Definition{
+		Name:        "http.request",
+		Description: "Time taken to serve an HTTP request, recorded by srvutil.NewRequestMetricsMiddleware.",
+		TagKeys:     []string{"route", "statusCode", "statusClass"},
+	})
 
-	HTTPRequest = &statsd.Timer{Name: "http.request"}
+	LogEntriesDropped = statsd.DefaultRegistry.Counter(statsd.Definition{
+		Name:        "logger.entries.dropped",
+		Description: "Log entries dropped by a logger.Sampler.",
+		TagKeys:     []string{"component", "level"},
+	})
 
-	ShellCommandRun = &statsd.Timer{Name: "shell.command.run"}
+	ShellCommandRun = statsd.DefaultRegistry.Timer(statsd.Definition{
+		Name:        "shell.command.run",
+		Description: "Time taken by a shell command to complete, since it was prepared.",
+		TagKeys:     []string{"success"},
+	})
+
+	TraceSpan = statsd.DefaultRegistry.Timer(statsd.Definition{
+		Name:        "trace.span",
+		Description: "Duration of the spans started with trace.Start.",
+		TagKeys:     []string{"span", "success"},
+	})
 )
+
+// CountDroppedLogEntries increments LogEntriesDropped, it is meant to be used as logger.SamplingConfig.OnDropped.
+// The message isn't used as a tag, since its cardinality is unbounded.
+func CountDroppedLogEntries(component string, level logrus.Level, _ string, dropped int) {
+	LogEntriesDropped.Count(context.Background(), int64(dropped), statsd.Tags{
+		"component": component,
+		"level":     level.String(),
+	})
+}
@@ -1,7 +1,9 @@
 package redact
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 )
 
@@ -59,3 +61,145 @@ func Headers(headers http.Header) map[string]string {
 
 	return redactedData
 }
+
+// maxDepth limits the recursion of Value, to protect against cyclic data structures.
+const maxDepth = 10
+
+// Value redacts a value recursively:
+//
+//   - Maps with string keys and structs: the values of sensitive keys and exported fields are filtered,
+//     and the other values are redacted recursively. Structs are converted to maps when they contain sensitive data.
+//   - Slices and arrays: the elements are redacted recursively, and the values of "KEY=VALUE" strings are filtered
+//     when the key is sensitive, such as in environment lists.
+//   - Pointers and interfaces are followed.
+//
+// Values are returned unmodified, with their original type, when they contain no sensitive data.
+// Errors and fmt.Stringer are never inspected.
+func Value(value interface{}) interface{} {
+	redacted, _ := redactValue(reflect.ValueOf(value), 0)
+	return redacted
+}
+
+// redactValue returns the redacted value, and whether it was modified.
+func redactValue(v reflect.Value, depth int) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	original := func() interface{} {
+		if v.CanInterface() {
+			return v.Interface()
+		}
+		return nil
+	}
+	if depth > maxDepth || !v.CanInterface() {
+		return original(), false
+	}
+
+	switch v.Interface().(type) {
+	case error, fmt.Stringer:
+		return v.Interface(), false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return original(), false
+		}
+		if redacted, changed := redactValue(v.Elem(), depth+1); changed {
+			return redacted, true
+		}
+		return original(), false
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return original(), false
+		}
+		redacted := make(map[string]interface{}, v.Len())
+		changed := false
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if IsSensitive(key) {
+				redacted[key] = placeholderText
+				changed = true
+				continue
+			}
+			elem, elemChanged := redactValue(iter.Value(), depth+1)
+			redacted[key] = elem
+			changed = changed || elemChanged
+		}
+		if changed {
+			return redacted, true
+		}
+		return original(), false
+
+	case reflect.Struct:
+		redacted := map[string]interface{}{}
+		changed := false
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if IsSensitive(field.Name) {
+				redacted[field.Name] = placeholderText
+				changed = true
+				continue
+			}
+			elem, elemChanged := redactValue(v.Field(i), depth+1)
+			redacted[field.Name] = elem
+			changed = changed || elemChanged
+		}
+		if changed {
+			return redacted, true
+		}
+		return original(), false
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return original(), false // []byte
+		}
+		redacted := make([]interface{}, v.Len())
+		changed := false
+		allStrings := true
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Interface && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.String {
+				s, sChanged := keyValueString(elem.String())
+				redacted[i] = s
+				changed = changed || sChanged
+				continue
+			}
+			allStrings = false
+			r, elemChanged := redactValue(elem, depth+1)
+			redacted[i] = r
+			changed = changed || elemChanged
+		}
+		if !changed {
+			return original(), false
+		}
+		if allStrings {
+			strs := make([]string, len(redacted))
+			for i, s := range redacted {
+				strs[i] = s.(string)
+			}
+			return strs, true
+		}
+		return redacted, true
+	}
+
+	return original(), false
+}
+
+// keyValueString filters the value of a "KEY=VALUE" string if the key is sensitive.
+func keyValueString(s string) (string, bool) {
+	key, _, ok := strings.Cut(s, "=")
+	if !ok || !IsSensitive(key) {
+		return s, false
+	}
+	return key + "=" + placeholderText, true
+}
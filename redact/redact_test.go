@@ -1,8 +1,10 @@
 package redact
 
 import (
+	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -107,3 +109,81 @@ func TestHeaders(t *testing.T) {
 		})
 	}
 }
+
+type testCredentials struct {
+	User     string
+	Password string
+	internal string
+}
+
+type testConfig struct {
+	Name        string
+	Credentials *testCredentials
+	Env         []string
+}
+
+func TestValue(t *testing.T) {
+	err := errors.New("token=abc")
+
+	testCases := []struct {
+		testName       string
+		input          interface{}
+		expectedResult interface{}
+	}{
+		{"nil", nil, nil},
+		{"string", "secret=1", "secret=1"},
+		{"error", err, err},
+		{"number", 42, 42},
+		{
+			"environment list",
+			[]string{"HOME=/root", "API_TOKEN=abc", "PATH"},
+			[]string{"HOME=/root", "API_TOKEN=[FILTERED]", "PATH"},
+		},
+		{
+			"arguments",
+			[]interface{}{"curl", "--password=abc", 1},
+			[]interface{}{"curl", "--password=[FILTERED]", 1},
+		},
+		{
+			"nested maps",
+			map[string]interface{}{"request": map[string]string{"Authorization": "Bearer abc", "path": "/"}, "id": 1},
+			map[string]interface{}{"request": map[string]interface{}{"Authorization": "[FILTERED]", "path": "/"}, "id": 1},
+		},
+		{
+			"structs",
+			&testConfig{Name: "app", Credentials: &testCredentials{User: "root", Password: "abc", internal: "x"}, Env: []string{"A=b"}},
+			map[string]interface{}{
+				"Name":        "app",
+				"Credentials": map[string]interface{}{"User": "root", "Password": "[FILTERED]"},
+				"Env":         []string{"A=b"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			require.Equal(t, tc.expectedResult, Value(tc.input))
+		})
+	}
+}
+
+func TestValue_unmodified(t *testing.T) {
+	config := &testConfig{Name: "app", Env: []string{"A=b"}}
+	require.Same(t, config, Value(config))
+
+	now := time.Now()
+	require.Equal(t, now, Value(now))
+
+	header := http.Header{"Accept": []string{"text/plain"}}
+	require.Equal(t, header, Value(header))
+}
+
+type cyclic struct {
+	Next *cyclic
+}
+
+func TestValue_cycle(t *testing.T) {
+	c := &cyclic{}
+	c.Next = c
+	require.NotPanics(t, func() { Value(c) })
+}
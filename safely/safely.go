@@ -1,7 +1,9 @@
 package safely
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/tomb.v2"
@@ -18,6 +20,67 @@ func Go(f func()) {
 	}()
 }
 
+// GoContext is the same as Go, but passes a Context which is not canceled with ctx, yet keeps its values,
+// such as the fields attached with logger.WithField and the tags attached with statsd.WithTag.
+// It is meant for work outliving the request which started it.
+//
+//	safely.GoContext(ctx, func(ctx context.Context) {
+//	  log(ctx, nil).Info("still has the request uuid")
+//	})
+func GoContext(ctx context.Context, f func(ctx context.Context)) {
+	ctx = withoutCancel{ctx}
+	Go(func() {
+		f(ctx)
+	})
+}
+
+// withoutCancel keeps the values of a Context, but neither its deadline nor its cancellation.
+// It is the same as context.WithoutCancel, which requires Go 1.21.
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (withoutCancel) Done() <-chan struct{} {
+	return nil
+}
+
+func (withoutCancel) Err() error {
+	return nil
+}
+
+// Value hides the cancellation cause of the parent, such that context.Cause returns nil like Err does.
+func (c withoutCancel) Value(key interface{}) interface{} {
+	if key == cancelCtxKey {
+		return nil
+	}
+	return c.Context.Value(key)
+}
+
+// cancelCtxKey is the unexported key context.Cause looks up the canceled parent with, captured by a probe Context.
+var cancelCtxKey = func() (key interface{}) {
+	context.Cause(keyProbe{Context: context.Background(), key: &key})
+	return key
+}()
+
+type keyProbe struct {
+	context.Context
+	key *interface{}
+}
+
+// Err reports the probe as canceled, since context.Cause only looks up the cause of canceled contexts.
+func (keyProbe) Err() error {
+	return context.Canceled
+}
+
+func (p keyProbe) Value(key interface{}) interface{} {
+	*p.key = key
+	return nil
+}
+
 // ErrPanicked is passed to bugsnag when we instrument a panic.
 type ErrPanicked struct {
 	val interface{}
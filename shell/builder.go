@@ -8,6 +8,9 @@ import (
 
 	"github.com/imdario/mergo"
 	"github.com/pkg/errors"
+
+	"github.com/Shopify/goose/metrics"
+	"github.com/Shopify/goose/trace"
 )
 
 type Builder interface {
@@ -60,11 +63,22 @@ func (w *wrapper) Prepare() Supervisor {
 	cmd := exec.Command(w.path, w.args...) //nolint:gosec
 	cmd.SysProcAttr = w.sysProcAttr
 
+	// Started before building the environment, such that the command continues the trace from this span.
+	w.spanCtx, w.span = trace.StartTimer(w.ctx, metrics.ShellCommandRun)
+
 	// Avoid modifying the w.env, so it doesn't log the sensitive OS environment
 	env := w.env
 	if w.osEnv {
 		env = append(env, os.Environ()...)
 	}
+	if traceEnv := trace.Env(w.spanCtx); len(traceEnv) > 0 {
+		if env == nil {
+			// A nil Env inherits the OS environment, which must be kept when adding the trace.
+			env = os.Environ()
+		}
+		// Appended last, such that it takes precedence over the TRACEPARENT inherited by this process.
+		env = append(env[:len(env):len(env)], traceEnv...)
+	}
 	cmd.Env = env
 
 	cmd.Dir = w.dir
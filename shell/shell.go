@@ -10,6 +10,7 @@ import (
 
 	"github.com/Shopify/goose/logger"
 	"github.com/Shopify/goose/statsd"
+	"github.com/Shopify/goose/trace"
 )
 
 var log = logger.New("shell")
@@ -47,6 +48,10 @@ type wrapper struct {
 	cmd         *exec.Cmd
 	sysProcAttr *syscall.SysProcAttr
 
+	// The span of the command, started by Prepare such that the command is its child, and ended by Wait.
+	span    *trace.Span
+	spanCtx context.Context
+
 	// When a context is provided and it is canceled while the process is
 	// running, we send SIGTERM to the process. if, after this period, the
 	// process is still running, we send SIGKILL. If left unspecified, the
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"sync"
 	"syscall"
 	"testing"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/trace"
 )
 
 func ExampleSupervisor_Wait() {
@@ -199,6 +202,38 @@ func TestCommandEnvDoesNotEval(t *testing.T) {
 	assert.Equal(t, []byte("$foo"), stdout)
 }
 
+func TestCommandPropagatesTrace(t *testing.T) {
+	exporter := trace.WithTestExporter(t)
+
+	sc := trace.NewRoot()
+	sc.TraceState = "vendor=value"
+	ctx := trace.WithSpanContext(context.Background(), sc)
+
+	stdout, _, err := NewBuilder(ctx, "sh", "-c", "echo -n $TRACEPARENT $TRACESTATE $HOME").
+		Prepare().
+		RunAndGetOutput()
+
+	require.NoError(t, err)
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "shell.command.run", spans[0].Name)
+	assert.Equal(t, sc.SpanID, spans[0].ParentSpanID)
+	// The command is a child of the span, and the OS environment is still inherited
+	assert.Equal(t, spans[0].SpanContext.Traceparent()+" vendor=value "+os.Getenv("HOME"), string(stdout))
+}
+
+func TestCommandSpanEndsWhenStartFails(t *testing.T) {
+	exporter := trace.WithTestExporter(t)
+	ctx := trace.WithSpanContext(context.Background(), trace.NewRoot())
+
+	err := New(ctx, "/does/not/exist").Run()
+	require.Error(t, err)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, err, spans[0].Err)
+}
+
 func TestCommandContextCancels(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cmd := NewBuilder(ctx, "bash", "-c", "read; echo -n foo").
@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/Shopify/goose/logger"
-	"github.com/Shopify/goose/metrics"
 )
 
 type Supervisor interface {
@@ -28,8 +27,12 @@ func (w *wrapper) Cmd() *exec.Cmd {
 	return w.cmd
 }
 
-func (w *wrapper) Start() error {
-	return w.cmd.Start()
+func (w *wrapper) Start() (err error) {
+	if err = w.cmd.Start(); err != nil {
+		// Wait isn't called when the command fails to start.
+		w.span.End(&err)
+	}
+	return err
 }
 
 func (w *wrapper) Run() error {
@@ -52,7 +55,7 @@ func (w *wrapper) RunAndGetOutput() ([]byte, []byte, error) {
 }
 
 func (w *wrapper) Wait() (err error) {
-	defer metrics.ShellCommandRun.StartTimer(w.ctx).SuccessFinish(&err)
+	defer w.span.End(&err)
 
 	cmd := w.cmd
 
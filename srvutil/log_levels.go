@@ -0,0 +1,93 @@
+package srvutil
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Shopify/goose/logger"
+)
+
+// LogLevels is the representation of the log levels served by NewLogLevelsServlet.
+type LogLevels struct {
+	// Default is the level of the components without an override, see logger.SetDefaultLevel.
+	Default string `json:"default,omitempty"`
+	// Components are the per-component overrides, see logger.SetComponentLevel.
+	Components map[string]string `json:"components"`
+}
+
+// NewLogLevelsServlet creates a Servlet exposing the log levels on /debug/loglevels:
+//
+//   - GET returns the LogLevels as JSON.
+//   - PUT replaces the component levels with the ones of the LogLevels in the body, and the default level if set.
+//     It responds with the resulting LogLevels.
+//
+// This allows debugging a component in production without a redeploy. It should be protected with UseServlet.
+func NewLogLevelsServlet() Servlet {
+	return InlineServlet(func(r *mux.Router) {
+		r.HandleFunc("/debug/loglevels", getLogLevels).Methods(http.MethodGet)
+		r.HandleFunc("/debug/loglevels", putLogLevels).Methods(http.MethodPut)
+	})
+}
+
+func getLogLevels(w http.ResponseWriter, r *http.Request) {
+	writeLogLevels(w, r)
+}
+
+func putLogLevels(w http.ResponseWriter, r *http.Request) {
+	body := LogLevels{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var defaultLevel logrus.Level
+	if body.Default != "" {
+		level, err := logrus.ParseLevel(body.Default)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defaultLevel = level
+	}
+
+	levels := make(map[string]logrus.Level, len(body.Components))
+	for component, name := range body.Components {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		levels[component] = level
+	}
+
+	// Only apply once everything is valid, and at once.
+	if body.Default != "" {
+		logger.SetLevels(defaultLevel, levels)
+	} else {
+		logger.SetComponentLevels(levels)
+	}
+
+	log(r.Context(), nil).
+		WithField("logLevels", logger.FormatComponentLevels(levels)).
+		WithField("defaultLevel", logger.DefaultLevel().String()).
+		Info("log levels updated")
+	writeLogLevels(w, r)
+}
+
+func writeLogLevels(w http.ResponseWriter, r *http.Request) {
+	levels := LogLevels{
+		Default:    logger.DefaultLevel().String(),
+		Components: map[string]string{},
+	}
+	for component, level := range logger.ComponentLevels() {
+		levels.Components[component] = level.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(levels); err != nil {
+		log(r.Context(), err).Warn("unable to write log levels")
+	}
+}
@@ -0,0 +1,55 @@
+package srvutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/logger"
+)
+
+func TestLogLevelsServlet(t *testing.T) {
+	defer logger.SetComponentLevels(logger.ComponentLevels())
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+
+	logrus.SetLevel(logrus.InfoLevel)
+	logger.SetComponentLevels(map[string]logrus.Level{"shell": logrus.DebugLevel})
+
+	router := mux.NewRouter()
+	CombineServlets(NewLogLevelsServlet()).RegisterRouting(router)
+
+	do := func(method string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/debug/loglevels", strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"default": "info", "components": {"shell": "debug"}}`, w.Body.String())
+
+	w = do(http.MethodPut, `{"default": "warn", "components": {"srvutil": "error"}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"default": "warning", "components": {"srvutil": "error"}}`, w.Body.String())
+	assert.Equal(t, logrus.WarnLevel, logger.DefaultLevel())
+	assert.Equal(t, map[string]logrus.Level{"srvutil": logrus.ErrorLevel}, logger.ComponentLevels())
+
+	// Invalid levels are rejected without applying anything
+	w = do(http.MethodPut, `{"components": {"shell": "debug", "srvutil": "loud"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, map[string]logrus.Level{"srvutil": logrus.ErrorLevel}, logger.ComponentLevels())
+
+	w = do(http.MethodPut, `not json`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodPost, `{}`)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -1,8 +1,13 @@
 package srvutil
 
 import (
+	"errors"
 	"net/http"
 	"time"
+
+	"github.com/Shopify/goose/metrics"
+	"github.com/Shopify/goose/statsd"
+	"github.com/Shopify/goose/trace"
 )
 
 type RequestMetricsMiddlewareConfig struct {
@@ -13,6 +18,9 @@ type RequestMetricsMiddlewareConfig struct {
 // NewRequestMetricsMiddleware records the time taken to serve a request, and logs request and response data.
 // Example tags: statusClass:2xx, statusCode:200
 // Should be added as a middleware after RequestContextMiddleware to benefit from its tags
+//
+// A span is started for each request, continuing the trace of the caller, see trace.StartRequest.
+// It fails for 5xx responses. Its duration is not recorded, since the Observer already does.
 func NewRequestMetricsMiddleware(c *RequestMetricsMiddlewareConfig) func(http.Handler) http.Handler {
 	if c.Observer == nil {
 		c.Observer = &DefaultRequestObserver{}
@@ -20,6 +28,9 @@ func NewRequestMetricsMiddleware(c *RequestMetricsMiddlewareConfig) func(http.Ha
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := trace.StartRequest(r.Context(), metrics.HTTPRequest.Name, nil, statsd.Tags{"method": r.Method})
+			r = r.WithContext(ctx)
+
 			c.Observer.BeforeRequest(r)
 
 			recorder := newHTTPRecorder(w, c.BodyLogPredicate)
@@ -29,6 +40,13 @@ func NewRequestMetricsMiddleware(c *RequestMetricsMiddlewareConfig) func(http.Ha
 			requestDuration := time.Since(startTime)
 
 			c.Observer.AfterRequest(r, recorder, requestDuration)
+
+			var err error
+			if recorder.StatusCode() >= http.StatusInternalServerError {
+				err = errors.New(http.StatusText(recorder.StatusCode()))
+			}
+			span.SetAttribute("statusCode", recorder.StatusCode())
+			span.End(&err)
 		})
 	}
 }
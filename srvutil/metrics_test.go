@@ -15,11 +15,13 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/tomb.v2"
 
 	"github.com/Shopify/goose/metrics"
 	"github.com/Shopify/goose/safely"
 	"github.com/Shopify/goose/statsd"
+	"github.com/Shopify/goose/trace"
 )
 
 const httpScheme = "http://"
@@ -182,3 +184,24 @@ func TestNewHTTPRecorder(t *testing.T) {
 		assert.True(t, ok, "recorder must implement http.Hijacker")
 	})
 }
+
+func TestRequestMetricsMiddleware_span(t *testing.T) {
+	exporter := trace.WithTestExporter(t)
+
+	handler := mux.NewRouter()
+	handler.Use(RequestContextMiddleware, NewRequestMetricsMiddleware(&RequestMetricsMiddlewareConfig{}))
+	handler.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(trace.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	span, ok := exporter.Find(metrics.HTTPRequest.Name)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID.String())
+	assert.Equal(t, map[string]interface{}{"method": http.MethodGet, "statusCode": http.StatusBadGateway}, span.Attributes)
+	assert.EqualError(t, span.Err, "Bad Gateway")
+}
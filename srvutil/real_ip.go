@@ -1,11 +1,28 @@
 package srvutil
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/goose/logger"
+)
+
+const (
+	RealIPHeaderKey       = "X-Real-IP"
+	ForwardedForHeaderKey = "X-Forwarded-For"
+	ForwardedHeaderKey    = "Forwarded"
+	PeerAddrKey           = "peerAddr"
 )
 
-const RealIPHeaderKey = "X-Real-IP"
+type peerAddrContextKey struct{}
 
+// RealIPMiddleware overwrites the RemoteAddr of the request with the X-Real-IP header.
+//
+// Deprecated: any client can spoof its address with the header. Use NewTrustedRealIPMiddleware instead.
 func RealIPMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -19,3 +36,194 @@ func RealIPMiddleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// TrustedProxies are the networks of the proxies allowed to report the address of the client.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses CIDRs, such as "10.0.0.0/8" or "2001:db8::/32". Single IPs are accepted.
+func ParseTrustedProxies(cidrs ...string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			addr = addr.Unmap()
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		if prefix.Addr().Is4In6() {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// Contains returns whether the address belongs to a trusted proxy.
+func (p TrustedProxies) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForwardingHeader is the header in which the trusted proxies report the address of the client.
+type ForwardingHeader int
+
+const (
+	// XForwardedForHeader is the X-Forwarded-For header, appended to by most proxies and load balancers.
+	XForwardedForHeader ForwardingHeader = iota
+	// ForwardedHeader is the Forwarded header (RFC 7239).
+	ForwardedHeader
+)
+
+// ClientAddr returns the address of the client, formatted as "host:port" like http.Request.RemoteAddr.
+//
+// If the peer is a trusted proxy, the hops of the header written by the proxies are walked right to left until an
+// address which isn't a trusted proxy. The walk stops at the last valid address if a hop is invalid or obfuscated.
+// The port is 0 when the hop has none. RemoteAddr is returned as is if the peer isn't trusted.
+//
+// Only the given header is read: proxies usually pass the other one through unchanged, such that the client
+// controls it.
+func (p TrustedProxies) ClientAddr(r *http.Request, header ForwardingHeader) string {
+	peer, ok := parseHop(r.RemoteAddr)
+	if !ok || !p.Contains(peer.Addr()) {
+		return r.RemoteAddr
+	}
+
+	var hops []string
+	switch header {
+	case ForwardedHeader:
+		hops = forwardedFor(r.Header)
+	default:
+		hops = forwardedForList(r.Header)
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHop(hops[i])
+		if !ok {
+			break
+		}
+		client = hop
+		if !p.Contains(hop.Addr()) {
+			break
+		}
+	}
+
+	if client == peer {
+		return r.RemoteAddr
+	}
+	return client.String()
+}
+
+// NewTrustedRealIPMiddleware overwrites the RemoteAddr of the requests forwarded by the trusted proxies with the
+// address of the client, as reported in the header written by the proxies, see TrustedProxies.ClientAddr.
+// The original RemoteAddr is kept in the Context for auditing, see PeerAddr, and logged as PeerAddrKey.
+func NewTrustedRealIPMiddleware(proxies TrustedProxies, header ForwardingHeader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer := r.RemoteAddr
+			ctx := context.WithValue(r.Context(), peerAddrContextKey{}, peer)
+			ctx = logger.WithField(ctx, PeerAddrKey, peer)
+
+			r = r.WithContext(ctx)
+			r.RemoteAddr = proxies.ClientAddr(r, header)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PeerAddr returns the address of the peer which sent the request, before NewTrustedRealIPMiddleware replaced
+// the RemoteAddr with the address of the client.
+func PeerAddr(ctx context.Context) (string, bool) {
+	peer, ok := ctx.Value(peerAddrContextKey{}).(string)
+	return peer, ok
+}
+
+// forwardedFor returns the "for" parameters of the Forwarded headers.
+// Elements without a "for" parameter are returned as empty strings, which are invalid hops.
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values(ForwardedHeaderKey) {
+		for _, element := range splitQuoted(value, ',') {
+			hop := ""
+			for _, pair := range splitQuoted(element, ';') {
+				key, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					hop = strings.Trim(v, `"`)
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// forwardedForList returns the addresses of the X-Forwarded-For headers.
+func forwardedForList(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values(ForwardedForHeaderKey) {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	return hops
+}
+
+// splitQuoted splits s around sep, ignoring the separators within quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseHop parses an address as found in RemoteAddr, X-Forwarded-For or the "for" parameter of Forwarded:
+// "192.0.2.1", "192.0.2.1:80", "2001:db8::1", "[2001:db8::1]" or "[2001:db8::1]:80".
+// Non-numeric ports, which are obfuscated in Forwarded, are replaced with 0.
+func parseHop(s string) (netip.AddrPort, bool) {
+	s = strings.TrimSpace(s)
+
+	host, port := s, ""
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return netip.AddrPort{}, false
+		}
+		host, port = s[1:end], strings.TrimPrefix(s[end+1:], ":")
+	} else if strings.Count(s, ":") == 1 {
+		host, port, _ = strings.Cut(s, ":")
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		portNumber = 0
+	}
+	return netip.AddrPortFrom(addr.Unmap(), uint16(portNumber)), true
+}
@@ -2,13 +2,17 @@ package srvutil_test
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/Shopify/goose/logger"
 	"github.com/Shopify/goose/srvutil"
 )
 
@@ -35,3 +39,163 @@ func TestRealIPMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "RemoteAddr: 127.0.0.17", w.Body.String())
 }
+
+func ExampleNewTrustedRealIPMiddleware() {
+	proxies, err := srvutil.ParseTrustedProxies("10.0.0.0/8", "2001:db8::/32")
+	if err != nil {
+		panic(err)
+	}
+
+	r := mux.NewRouter()
+	r.Use(srvutil.NewTrustedRealIPMiddleware(proxies, srvutil.XForwardedForHeader))
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := srvutil.ParseTrustedProxies("10.0.0.0/8", "192.0.2.1", "::ffff:172.16.0.0/108")
+	require.NoError(t, err)
+
+	assert.True(t, proxies.Contains(netip.MustParseAddr("10.1.2.3")))
+	assert.True(t, proxies.Contains(netip.MustParseAddr("::ffff:10.1.2.3")))
+	assert.True(t, proxies.Contains(netip.MustParseAddr("192.0.2.1")))
+	assert.False(t, proxies.Contains(netip.MustParseAddr("192.0.2.2")))
+	assert.True(t, proxies.Contains(netip.MustParseAddr("172.16.1.1")))
+
+	_, err = srvutil.ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = srvutil.ParseTrustedProxies("proxy")
+	assert.Error(t, err)
+}
+
+func TestTrustedProxies_ClientAddr(t *testing.T) {
+	proxies, err := srvutil.ParseTrustedProxies("10.0.0.0/8", "2001:db8::/32")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     srvutil.ForwardingHeader
+		headers    map[string][]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer",
+			remoteAddr: "203.0.113.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "203.0.113.1:1234",
+		},
+		{
+			name:       "no header",
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1:1234",
+		},
+		{
+			name:       "x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "198.51.100.1:0",
+		},
+		{
+			name:       "spoofed x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1, 198.51.100.1:5678", "10.0.0.2"}},
+			want:       "198.51.100.1:5678",
+		},
+		{
+			name:       "all trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:       "10.0.0.3:0",
+		},
+		{
+			name:       "invalid hop",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1, garbage, 10.0.0.2"}},
+			want:       "10.0.0.2:0",
+		},
+		{
+			name:       "x-forwarded-for ignores client forwarded",
+			remoteAddr: "10.0.0.2:1234",
+			headers: map[string][]string{
+				"Forwarded":       {"for=6.6.6.6"},
+				"X-Forwarded-For": {"203.0.113.9"},
+			},
+			want: "203.0.113.9:0",
+		},
+		{
+			name:       "x-forwarded-for ignores forwarded only",
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"Forwarded": {"for=6.6.6.6"}},
+			want:       "10.0.0.2:1234",
+		},
+		{
+			name:       "forwarded",
+			remoteAddr: "[2001:db8::1]:1234",
+			header:     srvutil.ForwardedHeader,
+			headers: map[string][]string{
+				"Forwarded":       {`for=192.0.2.43, for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.2;by="a,b"`},
+				"X-Forwarded-For": {"1.1.1.1"},
+			},
+			want: "192.0.2.43:0",
+		},
+		{
+			name:       "forwarded ignores client x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			header:     srvutil.ForwardedHeader,
+			headers:    map[string][]string{"X-Forwarded-For": {"6.6.6.6"}},
+			want:       "10.0.0.1:1234",
+		},
+		{
+			name:       "forwarded ipv6 client",
+			remoteAddr: "10.0.0.1:1234",
+			header:     srvutil.ForwardedHeader,
+			headers:    map[string][]string{"Forwarded": {`for="[2001:db9::17]:4711"`}},
+			want:       "[2001:db9::17]:4711",
+		},
+		{
+			name:       "forwarded obfuscated",
+			remoteAddr: "10.0.0.1:1234",
+			header:     srvutil.ForwardedHeader,
+			headers:    map[string][]string{"Forwarded": {`for=192.0.2.43, for=_hidden, for=10.0.0.2`}},
+			want:       "10.0.0.2:0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, values := range tt.headers {
+				for _, v := range values {
+					req.Header.Add(k, v)
+				}
+			}
+
+			got := proxies.ClientAddr(req, tt.header)
+			assert.Equal(t, tt.want, got)
+
+			_, _, err := net.SplitHostPort(got)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewTrustedRealIPMiddleware(t *testing.T) {
+	proxies, err := srvutil.ParseTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+
+	var r *http.Request
+	handler := srvutil.NewTrustedRealIPMiddleware(proxies, srvutil.XForwardedForHeader)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r = req
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "198.51.100.1:0", r.RemoteAddr)
+	peer, ok := srvutil.PeerAddr(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1:1234", peer)
+	assert.Equal(t, "10.0.0.1:1234", logger.GetLoggableValue(r.Context(), srvutil.PeerAddrKey))
+	assert.Equal(t, "10.0.0.1:1234", req.RemoteAddr) // The original request is not modified
+}
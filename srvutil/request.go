@@ -12,6 +12,7 @@ import (
 
 	"github.com/Shopify/goose/logger"
 	"github.com/Shopify/goose/statsd"
+	"github.com/Shopify/goose/trace"
 )
 
 var log = logger.New("srvutil")
@@ -66,14 +67,52 @@ func buildRouteContext(r *http.Request) context.Context {
 	return ctx
 }
 
+// BuildContext creates the Context of a request, with the route and the request ID as log fields,
+// and a span continuing the trace of the traceparent header, see trace.ContinueFromHeader.
+// The request ID is read, validated and generated according to DefaultRequestIDConfig, invalid IDs are replaced.
 func BuildContext(r *http.Request) (context.Context, string) {
+	ctx, id, _ := DefaultRequestIDConfig.BuildContext(r)
+	return ctx, id
+}
+
+// RequestContextMiddleware can be used with github.com/gorilla/mux:Router.Use or wrapping a Handler
+// It uses DefaultRequestIDConfig, see NewRequestContextMiddleware.
+func RequestContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		NewRequestContextMiddleware(DefaultRequestIDConfig)(next).ServeHTTP(w, r)
+	})
+}
+
+// BuildContext is the same as the BuildContext function, using this config for the request ID.
+// An error wrapping ErrInvalidRequestID is returned when the inbound request ID is invalid and the policy is
+// RejectInvalidRequestID, in which case the Context and the returned ID use a generated request ID.
+func (c RequestIDConfig) BuildContext(r *http.Request) (context.Context, string, error) {
 	ctx := buildRouteContext(r)
+	ctx = trace.ContinueFromHeader(ctx, r.Header)
+
+	var rejected error
+	id, header := c.inboundID(r.Header)
+	if id != "" {
+		if err := c.validate(id); err != nil {
+			// The invalid ID is not logged, since it is what the validation protects the logs from.
+			log(ctx, err).
+				WithField("header", header).
+				WithField("requestIDLength", len(id)).
+				Warn("invalid inbound request id")
+			id = ""
+			if c.InvalidPolicy == RejectInvalidRequestID {
+				rejected = err
+			}
+		}
+	}
 
-	// If caller specifies a request ID, use that instead of generating one
-	var id string
-	if id = r.Header.Get(UUIDHeaderKey); id == "" {
-		ctx, id = logger.WithUUID(ctx)
+	// If caller specifies a valid request ID, use that instead of generating one
+	if existing, ok := logger.GetLoggableValue(ctx, logger.UUIDKey).(string); ok && id == "" {
+		id = existing
 	} else {
+		if id == "" {
+			id = c.generate()
+		}
 		ctx = logger.WithField(ctx, logger.UUIDKey, id)
 	}
 
@@ -81,18 +120,24 @@ func BuildContext(r *http.Request) (context.Context, string) {
 		ctx = logger.WithField(ctx, UserEmailKey, email)
 	}
 
-	return ctx, id
+	return ctx, id, rejected
 }
 
-// RequestContextMiddleware can be used with github.com/gorilla/mux:Router.Use or wrapping a Handler
-func RequestContextMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx, id := BuildContext(r)
-		r = r.WithContext(ctx)
-		w.Header().Set(UUIDHeaderKey, id)
+// NewRequestContextMiddleware is the same as RequestContextMiddleware, using the config for the request ID.
+// Requests with an invalid request ID are answered with 400 Bad Request if the policy is RejectInvalidRequestID.
+func NewRequestContextMiddleware(c RequestIDConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, id, err := c.BuildContext(r)
+			w.Header().Set(c.responseHeader(), id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
 // replaceMatchableParts replaces mux templates "/{name:[a-z]+}" with "/hello/@name" to be tag-friendly
@@ -0,0 +1,114 @@
+package srvutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Shopify/goose/logger"
+)
+
+// InvalidRequestIDPolicy is the behaviour of BuildContext when an inbound request ID fails validation.
+type InvalidRequestIDPolicy int
+
+const (
+	// ReplaceInvalidRequestID generates a new request ID in place of the invalid one.
+	ReplaceInvalidRequestID InvalidRequestIDPolicy = iota
+	// RejectInvalidRequestID responds 400 Bad Request, when used with NewRequestContextMiddleware.
+	RejectInvalidRequestID
+)
+
+// ErrInvalidRequestID is returned by RequestIDConfig.BuildContext when an inbound request ID is rejected.
+var ErrInvalidRequestID = errors.New("invalid request id")
+
+// RequestIDConfig configures how BuildContext reads, validates and generates request IDs.
+type RequestIDConfig struct {
+	// Headers are the request headers containing the request ID, the first non-empty one is used.
+	// Defaults to UUIDHeaderKey.
+	Headers []string
+	// ResponseHeader is the response header set to the request ID by NewRequestContextMiddleware.
+	// Defaults to the first of Headers.
+	ResponseHeader string
+	// Generator generates the request ID when the request has none, or when it is replaced.
+	// Defaults to logger.NewID, see logger.SetIDGenerator.
+	Generator logger.IDGenerator
+	// MaxLength is the maximum length of an inbound request ID. Defaults to 128, negative disables the check.
+	MaxLength int
+	// AllowedChar returns whether an inbound request ID may contain a character.
+	// Defaults to IsRequestIDChar.
+	AllowedChar func(c rune) bool
+	// InvalidPolicy is the behaviour when an inbound request ID is invalid. Defaults to ReplaceInvalidRequestID.
+	InvalidPolicy InvalidRequestIDPolicy
+}
+
+// DefaultRequestIDConfig is used by BuildContext and RequestContextMiddleware.
+var DefaultRequestIDConfig = RequestIDConfig{}
+
+const defaultRequestIDMaxLength = 128
+
+// IsRequestIDChar allows ASCII letters and digits, and the punctuation found in UUIDs, ULIDs, base64 and
+// vendor-prefixed IDs: "-_.:+/=@".
+func IsRequestIDChar(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	switch c {
+	case '-', '_', '.', ':', '+', '/', '=', '@':
+		return true
+	}
+	return false
+}
+
+func (c RequestIDConfig) headers() []string {
+	if len(c.Headers) == 0 {
+		return []string{UUIDHeaderKey}
+	}
+	return c.Headers
+}
+
+func (c RequestIDConfig) responseHeader() string {
+	if c.ResponseHeader != "" {
+		return c.ResponseHeader
+	}
+	return c.headers()[0]
+}
+
+func (c RequestIDConfig) generate() string {
+	if c.Generator != nil {
+		return c.Generator()
+	}
+	return logger.NewID()
+}
+
+// validate returns an error describing why the request ID is invalid, without including it.
+func (c RequestIDConfig) validate(id string) error {
+	maxLength := c.MaxLength
+	if maxLength == 0 {
+		maxLength = defaultRequestIDMaxLength
+	}
+	if maxLength > 0 && len(id) > maxLength {
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidRequestID, maxLength)
+	}
+
+	allowed := c.AllowedChar
+	if allowed == nil {
+		allowed = IsRequestIDChar
+	}
+	for _, r := range id {
+		if !allowed(r) {
+			return fmt.Errorf("%w: contains disallowed character %U", ErrInvalidRequestID, r)
+		}
+	}
+	return nil
+}
+
+// inboundID returns the first non-empty request ID header, and the header name.
+func (c RequestIDConfig) inboundID(h http.Header) (string, string) {
+	for _, name := range c.headers() {
+		if id := h.Get(name); id != "" {
+			return id, name
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,106 @@
+package srvutil
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/logger"
+)
+
+func TestRequestIDConfig_BuildContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		valid  bool
+	}{
+		{name: "uuid", header: "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", valid: true},
+		{name: "vendor prefix", header: "edge:01ARZ3NDEKTSV4RRFFQ69G5FAV", valid: true},
+		{name: "newline", header: "abc\nlevel=error msg=injected"},
+		{name: "space", header: "abc def"},
+		{name: "non-ascii", header: "abcé"},
+		{name: "too long", header: strings.Repeat("a", 129)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := RequestIDConfig{Generator: func() string { return "generated" }}
+
+			r := newTestRequest("/path")
+			r.Header.Set(UUIDHeaderKey, tt.header)
+			ctx, id, err := c.BuildContext(r)
+			require.NoError(t, err)
+			if tt.valid {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.Equal(t, "generated", id)
+			}
+			assert.Equal(t, id, logger.GetLoggableValue(ctx, logger.UUIDKey))
+
+			c.InvalidPolicy = RejectInvalidRequestID
+			_, id, err = c.BuildContext(r)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidRequestID))
+				assert.NotContains(t, err.Error(), tt.header)
+				assert.Equal(t, "generated", id)
+			}
+		})
+	}
+}
+
+func TestRequestIDConfig_BuildContext_options(t *testing.T) {
+	c := RequestIDConfig{
+		Headers:     []string{"X-Correlation-ID", UUIDHeaderKey},
+		MaxLength:   -1,
+		AllowedChar: func(c rune) bool { return c != '!' },
+	}
+
+	r := newTestRequest("/path")
+	r.Header.Set(UUIDHeaderKey, "request")
+	_, id, err := c.BuildContext(r)
+	require.NoError(t, err)
+	assert.Equal(t, "request", id)
+
+	r.Header.Set("X-Correlation-ID", strings.Repeat("a b", 100))
+	_, id, err = c.BuildContext(r)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a b", 100), id)
+
+	r.Header.Set("X-Correlation-ID", "a!")
+	_, id, err = c.BuildContext(r)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f-]{36}$`, id) // Generated by logger.NewID
+}
+
+func TestNewRequestContextMiddleware(t *testing.T) {
+	called := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		assert.Equal(t, "abc", logger.GetLoggableValue(r.Context(), logger.UUIDKey))
+	})
+	middleware := NewRequestContextMiddleware(RequestIDConfig{
+		Headers:       []string{"X-Correlation-ID"},
+		InvalidPolicy: RejectInvalidRequestID,
+	})(handler)
+
+	r := newTestRequest("/path")
+	r.Header.Set("X-Correlation-ID", "abc")
+	w := httptest.NewRecorder()
+	middleware.ServeHTTP(w, r)
+	assert.True(t, called)
+	assert.Equal(t, "abc", w.Header().Get("X-Correlation-ID"))
+
+	called = false
+	r.Header.Set("X-Correlation-ID", "a\tb")
+	w = httptest.NewRecorder()
+	middleware.ServeHTTP(w, r)
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotEmpty(t, w.Header().Get("X-Correlation-ID"))
+}
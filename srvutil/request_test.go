@@ -8,8 +8,10 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/Shopify/goose/logger"
+	"github.com/Shopify/goose/trace"
 )
 
 func TestBuildContext(t *testing.T) {
@@ -23,6 +25,26 @@ func TestBuildContext(t *testing.T) {
 	assert.Nil(t, entry.Data[RouteKey]) // No route info
 }
 
+func TestBuildContext_trace(t *testing.T) {
+	r := newTestRequest("/path")
+	ctx, _ := BuildContext(r)
+	sc, ok := trace.FromContext(ctx)
+	require.True(t, ok)
+	assert.True(t, sc.IsValid())
+	assert.Equal(t, sc.TraceID.String(), logger.GetLoggableValue(ctx, logger.TraceIDKey))
+	assert.Equal(t, sc.SpanID.String(), logger.GetLoggableValue(ctx, logger.SpanIDKey))
+
+	r = newTestRequest("/path")
+	r.Header.Set(trace.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set(trace.TracestateHeader, "vendor=value")
+	ctx, _ = BuildContext(r)
+	sc, ok = trace.FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.NotEqual(t, "00f067aa0ba902b7", sc.SpanID.String()) // New span in the same trace
+	assert.Equal(t, "vendor=value", sc.TraceState)
+}
+
 func TestRequestContextMiddleware(t *testing.T) {
 	var r *http.Request
 	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,299 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Percentiles reported by the aggregating backend for histograms, distributions and timings,
+// along with the suffix appended to the metric name.
+var aggregatedPercentiles = []struct {
+	suffix   string
+	quantile float64
+}{
+	{".median", 0.5},
+	{".95percentile", 0.95},
+	{".99percentile", 0.99},
+}
+
+// NewAggregatingBackend creates a Backend that aggregates metrics in memory, per name and set of tags,
+// and submits them to inner every interval, as well as when flushed or closed:
+//
+//   - Counts are summed.
+//   - Gauges keep the last value.
+//   - Sets keep the unique values.
+//   - Histograms, distributions and timings are summarized as gauges suffixed with .min, .max, .avg, .median,
+//     .95percentile and .99percentile, and a count suffixed with .count. Timings are reported in milliseconds.
+//     The count, min, max and avg are exact, the percentiles are estimated from a uniform sample of at most
+//     1024 values per interval, such that memory doesn't grow with the rate of the metric.
+//
+// This makes chatty backends, like NewLogBackend or NewForwardingBackend, usable in hot loops.
+// Aggregated metrics are submitted with a background Context and a rate of 1.
+func NewAggregatingBackend(inner Backend, interval time.Duration) Backend {
+	b := &aggregatingBackend{
+		inner:   inner,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	b.reset()
+	go b.flushLoop(interval)
+	return b
+}
+
+type aggregatingBackend struct {
+	inner Backend
+
+	l       sync.Mutex
+	counts  map[string]*aggregatedCount
+	gauges  map[string]*aggregatedGauge
+	sets    map[string]*aggregatedSet
+	samples map[string]*aggregatedSamples
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+type aggregationKey struct {
+	name string
+	tags []string
+}
+
+type aggregatedCount struct {
+	aggregationKey
+	value int64
+}
+
+type aggregatedGauge struct {
+	aggregationKey
+	value float64
+}
+
+type aggregatedSet struct {
+	aggregationKey
+	values map[string]struct{}
+}
+
+// maxAggregatedSamples is the size of the reservoir of aggregatedSamples.
+const maxAggregatedSamples = 1024
+
+// aggregatedSamples summarizes the values of a histogram, distribution or timing in a fixed amount of memory:
+// the count, sum, min and max are kept as running values, and the percentiles are estimated from a reservoir.
+type aggregatedSamples struct {
+	aggregationKey
+	count    int64
+	sum      float64
+	min, max float64
+	// reservoir is a uniform random sample of the values, see add.
+	reservoir []float64
+}
+
+// add records a value. The reservoir keeps each of the values seen so far with the same probability
+// (Vitter's algorithm R).
+func (s *aggregatedSamples) add(value float64) {
+	if s.count == 0 || value < s.min {
+		s.min = value
+	}
+	if s.count == 0 || value > s.max {
+		s.max = value
+	}
+	s.count++
+	s.sum += value
+
+	if len(s.reservoir) < maxAggregatedSamples {
+		s.reservoir = append(s.reservoir, value)
+	} else if i := rand.Int63n(s.count); i < maxAggregatedSamples {
+		s.reservoir[i] = value
+	}
+}
+
+// newAggregationKey identifies a metric by its type, name and tags, regardless of the order of the tags.
+func newAggregationKey(mType, name string, tags []string) (string, aggregationKey) {
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+	return mType + "|" + name + "|" + strings.Join(sorted, ","), aggregationKey{name: name, tags: sorted}
+}
+
+func (b *aggregatingBackend) reset() {
+	b.counts = map[string]*aggregatedCount{}
+	b.gauges = map[string]*aggregatedGauge{}
+	b.sets = map[string]*aggregatedSet{}
+	b.samples = map[string]*aggregatedSamples{}
+}
+
+func (b *aggregatingBackend) Gauge(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	id, key := newAggregationKey(GaugeType, name, tags)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	g, ok := b.gauges[id]
+	if !ok {
+		g = &aggregatedGauge{aggregationKey: key}
+		b.gauges[id] = g
+	}
+	g.value = value
+	return nil
+}
+
+func (b *aggregatingBackend) Count(_ context.Context, name string, value int64, tags []string, _ float64) error {
+	id, key := newAggregationKey(CountType, name, tags)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	c, ok := b.counts[id]
+	if !ok {
+		c = &aggregatedCount{aggregationKey: key}
+		b.counts[id] = c
+	}
+	c.value += value
+	return nil
+}
+
+func (b *aggregatingBackend) Histogram(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	b.addSample(HistogramType, name, value, tags)
+	return nil
+}
+
+func (b *aggregatingBackend) Distribution(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	b.addSample(DistributionType, name, value, tags)
+	return nil
+}
+
+func (b *aggregatingBackend) Set(_ context.Context, name string, value string, tags []string, _ float64) error {
+	id, key := newAggregationKey(SetType, name, tags)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	s, ok := b.sets[id]
+	if !ok {
+		s = &aggregatedSet{aggregationKey: key, values: map[string]struct{}{}}
+		b.sets[id] = s
+	}
+	s.values[value] = struct{}{}
+	return nil
+}
+
+func (b *aggregatingBackend) Timing(_ context.Context, name string, value time.Duration, tags []string, _ float64) error {
+	b.addSample(TimingType, name, value.Seconds()*1000, tags)
+	return nil
+}
+
+func (b *aggregatingBackend) addSample(mType string, name string, value float64, tags []string) {
+	id, key := newAggregationKey(mType, name, tags)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	s, ok := b.samples[id]
+	if !ok {
+		s = &aggregatedSamples{aggregationKey: key}
+		b.samples[id] = s
+	}
+	s.add(value)
+}
+
+// Event sends the Event to the inner backend right away, if it implements EventSender.
+func (b *aggregatingBackend) Event(ctx context.Context, e Event) error {
+	if s, ok := b.inner.(EventSender); ok {
+		return s.Event(ctx, e)
+	}
+	return nil
+}
+
+// ServiceCheck sends the ServiceCheck to the inner backend right away, if it implements ServiceCheckSender.
+func (b *aggregatingBackend) ServiceCheck(ctx context.Context, sc ServiceCheck) error {
+	if s, ok := b.inner.(ServiceCheckSender); ok {
+		return s.ServiceCheck(ctx, sc)
+	}
+	return nil
+}
+
+// Flush submits the aggregated metrics to the inner backend, then flushes it if it implements Flusher.
+func (b *aggregatingBackend) Flush() error {
+	return errors.Join(b.submit(), flush(b.inner))
+}
+
+// Close stops the flush loop, submits the aggregated metrics, then flushes and closes the inner backend.
+func (b *aggregatingBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		<-b.stopped
+
+		err = errors.Join(b.submit(), FlushAndClose(b.inner))
+	})
+	return err
+}
+
+// submit sends the aggregated metrics to the inner backend and resets them.
+func (b *aggregatingBackend) submit() error {
+	b.l.Lock()
+	counts, gauges, sets, samples := b.counts, b.gauges, b.sets, b.samples
+	b.reset()
+	b.l.Unlock()
+
+	ctx := context.Background()
+	var errs []error
+	record := func(err error) {
+		errs = append(errs, err)
+	}
+
+	for _, c := range counts {
+		record(b.inner.Count(ctx, c.name, c.value, c.tags, 1))
+	}
+	for _, g := range gauges {
+		record(b.inner.Gauge(ctx, g.name, g.value, g.tags, 1))
+	}
+	for _, s := range sets {
+		for value := range s.values {
+			record(b.inner.Set(ctx, s.name, value, s.tags, 1))
+		}
+	}
+	for _, s := range samples {
+		sort.Float64s(s.reservoir)
+
+		record(b.inner.Gauge(ctx, s.name+".min", s.min, s.tags, 1))
+		record(b.inner.Gauge(ctx, s.name+".max", s.max, s.tags, 1))
+		record(b.inner.Gauge(ctx, s.name+".avg", s.sum/float64(s.count), s.tags, 1))
+		for _, p := range aggregatedPercentiles {
+			record(b.inner.Gauge(ctx, s.name+p.suffix, percentile(s.reservoir, p.quantile), s.tags, 1))
+		}
+		record(b.inner.Count(ctx, s.name+".count", s.count, s.tags, 1))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *aggregatingBackend) flushLoop(interval time.Duration) {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			warnIfError(context.Background(), b.Flush())
+		}
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, quantile float64) float64 {
+	rank := int(math.Ceil(quantile * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
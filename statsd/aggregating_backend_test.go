@@ -0,0 +1,99 @@
+package statsd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregatingBackend(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecordingBackend()
+	b := NewAggregatingBackend(recorder, time.Hour)
+	defer b.(Closer).Close()
+
+	for i := 1; i <= 100; i++ {
+		require.NoError(t, b.Count(ctx, "count", 2, []string{"a:1", "b:2"}, 1))
+		require.NoError(t, b.Gauge(ctx, "gauge", float64(i), nil, 1))
+		require.NoError(t, b.Set(ctx, "set", []string{"x", "y"}[i%2], nil, 1))
+		require.NoError(t, b.Histogram(ctx, "histogram", float64(i), nil, 1))
+		require.NoError(t, b.Timing(ctx, "timing", time.Duration(i)*time.Millisecond, nil, 1))
+	}
+	// Tag order doesn't matter
+	require.NoError(t, b.Count(ctx, "count", 1, []string{"b:2", "a:1"}, 1))
+	require.NoError(t, b.Count(ctx, "count", 1, []string{"a:other"}, 1))
+
+	assert.Empty(t, recorder.Metrics())
+	require.NoError(t, b.(Flusher).Flush())
+
+	assert.Equal(t, int64(201), recorder.CountTotal("count", "a:1", "b:2"))
+	assert.Equal(t, int64(1), recorder.CountTotal("count", "a:other"))
+	assert.Equal(t, []float64{100}, recorder.Gauges("gauge"))
+	assert.ElementsMatch(t, []string{"x", "y"}, recorder.Sets("set"))
+
+	for _, name := range []string{"histogram", "timing"} {
+		assert.Equal(t, []float64{1}, recorder.Gauges(name+".min"))
+		assert.Equal(t, []float64{100}, recorder.Gauges(name+".max"))
+		assert.Equal(t, []float64{50.5}, recorder.Gauges(name+".avg"))
+		assert.Equal(t, []float64{50}, recorder.Gauges(name+".median"))
+		assert.Equal(t, []float64{95}, recorder.Gauges(name+".95percentile"))
+		assert.Equal(t, []float64{99}, recorder.Gauges(name+".99percentile"))
+		assert.Equal(t, int64(100), recorder.CountTotal(name+".count"))
+	}
+
+	// Aggregates are reset after being flushed
+	recorder.Reset()
+	require.NoError(t, b.(Flusher).Flush())
+	assert.Empty(t, recorder.Metrics())
+}
+
+func TestAggregatingBackend_samplesAreBounded(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecordingBackend()
+	b := NewAggregatingBackend(recorder, time.Hour)
+	defer b.(Closer).Close()
+
+	const n = 100 * maxAggregatedSamples
+	for i := 1; i <= n; i++ {
+		require.NoError(t, b.Distribution(ctx, "distribution", float64(i), nil, 1))
+	}
+	for _, s := range b.(*aggregatingBackend).samples {
+		assert.Len(t, s.reservoir, maxAggregatedSamples)
+	}
+
+	require.NoError(t, b.(Flusher).Flush())
+	assert.Equal(t, []float64{1}, recorder.Gauges("distribution.min"))
+	assert.Equal(t, []float64{n}, recorder.Gauges("distribution.max"))
+	assert.Equal(t, []float64{(n + 1) / 2.0}, recorder.Gauges("distribution.avg"))
+	assert.Equal(t, int64(n), recorder.CountTotal("distribution.count"))
+	assert.InEpsilon(t, n/2, recorder.Gauges("distribution.median")[0], 0.1)
+	assert.InEpsilon(t, n*0.95, recorder.Gauges("distribution.95percentile")[0], 0.05)
+}
+
+func TestAggregatingBackend_interval(t *testing.T) {
+	recorder := NewRecordingBackend()
+	b := NewAggregatingBackend(recorder, 10*time.Millisecond)
+	defer b.(Closer).Close()
+
+	require.NoError(t, b.Count(context.Background(), "count", 1, nil, 1))
+	assert.Eventually(t, func() bool {
+		return recorder.CountTotal("count") == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAggregatingBackend_close(t *testing.T) {
+	inner := &closingBackend{RecordingBackend: NewRecordingBackend()}
+	b := NewAggregatingBackend(inner, time.Hour)
+
+	require.NoError(t, b.Count(context.Background(), "count", 1, nil, 1))
+	assert.EqualError(t, b.(Closer).Close(), "close error")
+	assert.Equal(t, int64(1), inner.CountTotal("count"))
+	assert.Equal(t, 1, inner.closed)
+
+	// Closing again is a no-op
+	assert.NoError(t, b.(Closer).Close())
+	assert.Equal(t, 1, inner.closed)
+}
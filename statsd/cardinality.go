@@ -0,0 +1,139 @@
+package statsd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OtherTagValue replaces the tag values exceeding the cardinality limit.
+const OtherTagValue = "other"
+
+// CardinalityExceededMetric is the counter incremented every time a tag value is replaced by OtherTagValue.
+// It is tagged with the metric and the tag key that exceeded the limit.
+const CardinalityExceededMetric = "statsd.cardinality_exceeded"
+
+// CardinalityLimiter restricts the tags submitted with a metric, to protect against high cardinality tags.
+type CardinalityLimiter interface {
+	// Limit returns the tags to be submitted with the metric name.
+	// The tags slice must not be modified, a copy must be returned instead.
+	Limit(ctx context.Context, name string, tags []string) []string
+}
+
+var cardinalityLimiter CardinalityLimiter
+
+// SetCardinalityLimiter sets the CardinalityLimiter applied to every metric. nil disables the limit, which is the default.
+// It should be called once at application startup, along with SetBackend.
+func SetCardinalityLimiter(l CardinalityLimiter) {
+	cardinalityLimiter = l
+}
+
+// NewCardinalityLimiter creates a CardinalityLimiter tracking the distinct values seen per tag key, per metric.
+// Once maxValues distinct values have been seen, new values are replaced with OtherTagValue.
+// The values already seen are still submitted as usual.
+//
+// keyLimits overrides maxValues for specific tag keys. A limit of 0 or less means unlimited.
+//
+// Every replacement increments CardinalityExceededMetric, and a warning is logged the first time a metric's tag key
+// exceeds its limit.
+func NewCardinalityLimiter(maxValues int, keyLimits map[string]int) CardinalityLimiter {
+	return &valueCountLimiter{
+		maxValues: maxValues,
+		keyLimits: keyLimits,
+		metrics:   map[string]map[string]*tagValues{},
+	}
+}
+
+type valueCountLimiter struct {
+	maxValues int
+	keyLimits map[string]int
+
+	l       sync.Mutex
+	metrics map[string]map[string]*tagValues // metric -> tag key -> values
+}
+
+type tagValues struct {
+	values   map[string]struct{}
+	exceeded bool
+}
+
+func (c *valueCountLimiter) Limit(ctx context.Context, name string, tags []string) []string {
+	var limited []string
+
+	for i, tag := range tags {
+		key, value := splitTag(tag)
+		if c.allow(ctx, name, key, value) {
+			continue
+		}
+
+		if limited == nil {
+			limited = append([]string{}, tags...)
+		}
+		limited[i] = key + ":" + OtherTagValue
+	}
+
+	if limited == nil {
+		return tags
+	}
+	sort.Strings(limited)
+	return limited
+}
+
+func (c *valueCountLimiter) allow(ctx context.Context, name, key, value string) bool {
+	limit := c.maxValues
+	if l, ok := c.keyLimits[key]; ok {
+		limit = l
+	}
+	if limit <= 0 || value == OtherTagValue {
+		return true
+	}
+
+	c.l.Lock()
+	keys, ok := c.metrics[name]
+	if !ok {
+		keys = map[string]*tagValues{}
+		c.metrics[name] = keys
+	}
+	tv, ok := keys[key]
+	if !ok {
+		tv = &tagValues{values: map[string]struct{}{}}
+		keys[key] = tv
+	}
+
+	if _, ok := tv.values[value]; ok {
+		c.l.Unlock()
+		return true
+	}
+	if len(tv.values) < limit {
+		tv.values[value] = struct{}{}
+		c.l.Unlock()
+		return true
+	}
+
+	firstExceeded := !tv.exceeded
+	tv.exceeded = true
+	c.l.Unlock()
+
+	if firstExceeded {
+		log(ctx, nil).
+			WithField("metric", name).
+			WithField("tagKey", key).
+			WithField("tagValue", value).
+			WithField("limit", limit).
+			Warn("statsd tag cardinality exceeded, replacing new values with " + OtherTagValue)
+	}
+
+	// Submitted directly to the backend, this metric must not go through the limiter itself.
+	exceededTags := []string{"metric:" + name, "tag:" + key}
+	warnIfError(ctx, GetBackend().Count(ctx, CardinalityExceededMetric, 1, exceededTags, 1))
+	return false
+}
+
+// splitTag splits a "key:value" tag. Tags without a colon have an empty value.
+func splitTag(tag string) (string, string) {
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
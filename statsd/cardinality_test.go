@@ -0,0 +1,60 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardinalityLimiter(t *testing.T) {
+	b := WithTestBackend(t)
+	SetCardinalityLimiter(NewCardinalityLimiter(2, map[string]int{"route": 0, "status": 1}))
+	defer SetCardinalityLimiter(nil)
+
+	ctx := WithTag(context.Background(), "env", "test")
+	metric := &Counter{Name: "requests"}
+
+	for i := 0; i < 4; i++ {
+		metric.Incr(ctx, Tags{
+			"shop":   i,
+			"route":  fmt.Sprintf("/route/%d", i),
+			"status": 200 + i,
+		})
+	}
+	// Values seen before the limit was reached are still accepted
+	metric.Incr(ctx, Tags{"shop": 1, "route": "/route/1", "status": 200})
+
+	assert.Equal(t, int64(5), b.CountTotal("requests", "env:test"))
+	assert.Equal(t, int64(1), b.CountTotal("requests", "shop:0"))
+	assert.Equal(t, int64(2), b.CountTotal("requests", "shop:1"))
+	assert.Equal(t, int64(2), b.CountTotal("requests", "shop:other"))
+
+	// Unlimited key
+	assert.Equal(t, int64(1), b.CountTotal("requests", "route:/route/3"))
+	assert.Equal(t, int64(0), b.CountTotal("requests", "route:other"))
+
+	// Overridden limit
+	assert.Equal(t, int64(2), b.CountTotal("requests", "status:200"))
+	assert.Equal(t, int64(3), b.CountTotal("requests", "status:other"))
+
+	assert.Equal(t, int64(2), b.CountTotal(CardinalityExceededMetric, "metric:requests", "tag:shop"))
+	assert.Equal(t, int64(3), b.CountTotal(CardinalityExceededMetric, "metric:requests", "tag:status"))
+	assert.Equal(t, int64(0), b.CountTotal(CardinalityExceededMetric, "tag:env"))
+}
+
+func TestCardinalityLimiter_perMetric(t *testing.T) {
+	l := NewCardinalityLimiter(1, nil)
+	ctx := context.Background()
+	WithTestBackend(t)
+
+	assert.Equal(t, []string{"shop:1"}, l.Limit(ctx, "a", []string{"shop:1"}))
+	assert.Equal(t, []string{"shop:other"}, l.Limit(ctx, "a", []string{"shop:2"}))
+	assert.Equal(t, []string{"shop:2"}, l.Limit(ctx, "b", []string{"shop:2"}))
+
+	// Input is not modified
+	tags := []string{"a:1", "shop:3"}
+	assert.Equal(t, []string{"a:1", "shop:other"}, l.Limit(ctx, "a", tags))
+	assert.Equal(t, []string{"a:1", "shop:3"}, tags)
+}
@@ -11,8 +11,8 @@ type Counter collector
 //
 // The last parameter is an arbitrary array of tags as maps.
 func (c *Counter) Count(ctx context.Context, n int64, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Count(ctx, c.Name, n, tags, c.Rate.Rate()))
+	tags := metricTags(ctx, c.Name, ts...)
+	warnIfError(ctx, GetBackend().Count(ctx, c.Name, n, tags, c.Rate.Rate()))
 }
 
 // Incr is basically the same as Count(1)
@@ -62,3 +62,33 @@ func (b *datadogBackend) Set(_ context.Context, name string, value string, tags
 func (b *datadogBackend) Timing(_ context.Context, name string, value time.Duration, tags []string, rate float64) error {
 	return b.client.Timing(name, value, tags, rate)
 }
+
+func (b *datadogBackend) Event(_ context.Context, e Event) error {
+	return b.client.Event(&statsd.Event{
+		Title:          e.Title,
+		Text:           e.Text,
+		Timestamp:      e.Timestamp,
+		AggregationKey: e.AggregationKey,
+		Priority:       statsd.EventPriority(e.Priority),
+		AlertType:      statsd.EventAlertType(e.AlertType),
+		Tags:           e.Tags,
+	})
+}
+
+func (b *datadogBackend) ServiceCheck(_ context.Context, sc ServiceCheck) error {
+	return b.client.ServiceCheck(&statsd.ServiceCheck{
+		Name:      sc.Name,
+		Status:    statsd.ServiceCheckStatus(sc.Status),
+		Timestamp: sc.Timestamp,
+		Message:   sc.Message,
+		Tags:      sc.Tags,
+	})
+}
+
+func (b *datadogBackend) Flush() error {
+	return b.client.Flush()
+}
+
+func (b *datadogBackend) Close() error {
+	return b.client.Close()
+}
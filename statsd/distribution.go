@@ -8,6 +8,6 @@ type Distribution collector
 
 // The last parameter is an arbitrary array of tags as maps.
 func (d *Distribution) Distribution(ctx context.Context, n float64, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Distribution(ctx, d.Name, n, tags, d.Rate.Rate()))
+	tags := metricTags(ctx, d.Name, ts...)
+	warnIfError(ctx, GetBackend().Distribution(ctx, d.Name, n, tags, d.Rate.Rate()))
 }
@@ -0,0 +1,114 @@
+package statsd
+
+import (
+	"context"
+	"time"
+)
+
+// Types of the non-metric payloads, as passed to a ForwardHandler and a Filter.
+// The value passed to a ForwardHandler is an Event or a ServiceCheck respectively, and the name is the event title
+// or the service check name.
+const (
+	EventType        = "event"
+	ServiceCheckType = "service_check"
+)
+
+// EventPriority is the priority of an Event.
+type EventPriority string
+
+const (
+	EventPriorityNormal EventPriority = "normal"
+	EventPriorityLow    EventPriority = "low"
+)
+
+// EventAlertType is the alert type of an Event.
+type EventAlertType string
+
+const (
+	EventAlertTypeInfo    EventAlertType = "info"
+	EventAlertTypeError   EventAlertType = "error"
+	EventAlertTypeWarning EventAlertType = "warning"
+	EventAlertTypeSuccess EventAlertType = "success"
+)
+
+// Event is a DogStatsD event, displayed on dashboards alongside metrics.
+// https://docs.datadoghq.com/events/guides/dogstatsd/
+type Event struct {
+	Title string
+	Text  string
+	// Timestamp defaults to the time the event is received.
+	Timestamp time.Time
+	// AggregationKey groups events together.
+	AggregationKey string
+	// Priority defaults to EventPriorityNormal.
+	Priority EventPriority
+	// AlertType defaults to EventAlertTypeInfo.
+	AlertType EventAlertType
+	Tags      []string
+}
+
+// ServiceCheckStatus is the status of a ServiceCheck.
+type ServiceCheckStatus int
+
+const (
+	ServiceCheckOK ServiceCheckStatus = iota
+	ServiceCheckWarn
+	ServiceCheckCritical
+	ServiceCheckUnknown
+)
+
+func (s ServiceCheckStatus) String() string {
+	switch s {
+	case ServiceCheckOK:
+		return "ok"
+	case ServiceCheckWarn:
+		return "warn"
+	case ServiceCheckCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ServiceCheck is a DogStatsD service check, reporting the status of a service.
+// https://docs.datadoghq.com/developers/service_checks/dogstatsd_service_checks_submission/
+type ServiceCheck struct {
+	Name   string
+	Status ServiceCheckStatus
+	// Timestamp defaults to the time the service check is received.
+	Timestamp time.Time
+	Message   string
+	Tags      []string
+}
+
+// EventSender is implemented by the backends supporting events.
+type EventSender interface {
+	Event(ctx context.Context, e Event) error
+}
+
+// ServiceCheckSender is implemented by the backends supporting service checks.
+type ServiceCheckSender interface {
+	ServiceCheck(ctx context.Context, sc ServiceCheck) error
+}
+
+// SendEvent sends an Event through the current backend, if it implements EventSender.
+// The tags of the Context and the given tags are appended to the event tags.
+func SendEvent(ctx context.Context, e Event, ts ...Tags) {
+	s, ok := GetBackend().(EventSender)
+	if !ok {
+		return
+	}
+	e.Tags = append(e.Tags[:len(e.Tags):len(e.Tags)], getStatsTags(ctx, ts...)...)
+	warnIfError(ctx, s.Event(ctx, e))
+}
+
+// SendServiceCheck sends a ServiceCheck through the current backend, if it implements ServiceCheckSender.
+// The tags of the Context and the given tags are appended to the service check tags.
+func SendServiceCheck(ctx context.Context, sc ServiceCheck, ts ...Tags) {
+	s, ok := GetBackend().(ServiceCheckSender)
+	if !ok {
+		return
+	}
+	sc.Tags = append(sc.Tags[:len(sc.Tags):len(sc.Tags)], getStatsTags(ctx, ts...)...)
+	warnIfError(ctx, s.ServiceCheck(ctx, sc))
+}
@@ -0,0 +1,142 @@
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendEvent(t *testing.T) {
+	recorder := WithTestBackend(t)
+	ctx := WithTag(context.Background(), "context", "ok")
+
+	SendEvent(ctx, Event{Title: "deploy", Text: "deployed", AlertType: EventAlertTypeSuccess, Tags: []string{"event:ok"}}, Tags{"extra": "ok"})
+
+	events := recorder.Events("deploy")
+	require.Len(t, events, 1)
+	assert.Equal(t, Event{
+		Title:     "deploy",
+		Text:      "deployed",
+		AlertType: EventAlertTypeSuccess,
+		Tags:      []string{"event:ok", "context:ok", "extra:ok"},
+	}, events[0])
+}
+
+func TestSendServiceCheck(t *testing.T) {
+	recorder := WithTestBackend(t)
+	ctx := WithTag(context.Background(), "context", "ok")
+
+	SendServiceCheck(ctx, ServiceCheck{Name: "db.reachable", Status: ServiceCheckCritical, Message: "timeout"})
+
+	checks := recorder.ServiceChecks("db.reachable", "context:ok")
+	require.Len(t, checks, 1)
+	assert.Equal(t, ServiceCheckCritical, checks[0].Status)
+	assert.Equal(t, "critical", checks[0].Status.String())
+	assert.Equal(t, "timeout", checks[0].Message)
+}
+
+func TestSendEvent_unsupportedBackend(t *testing.T) {
+	defer SetBackend(NewNullBackend())
+
+	SetBackend(struct{ Backend }{NewNullBackend()})
+	assert.NotPanics(t, func() {
+		SendEvent(context.Background(), Event{Title: "ignored"})
+		SendServiceCheck(context.Background(), ServiceCheck{Name: "ignored"})
+	})
+}
+
+func TestEvents_wrappingBackends(t *testing.T) {
+	ctx := context.Background()
+	all := NewRecordingBackend()
+	checks := NewRecordingBackend()
+	aggregated := NewRecordingBackend()
+
+	b := NewMultiBackend(
+		all,
+		NewFilteredBackend(checks, MetricTypeFilter(ServiceCheckType)),
+		NewAggregatingBackend(aggregated, time.Hour),
+		NewNullBackend(),
+	)
+	defer FlushAndClose(b)
+
+	require.NoError(t, b.(EventSender).Event(ctx, Event{Title: "event"}))
+	require.NoError(t, b.(ServiceCheckSender).ServiceCheck(ctx, ServiceCheck{Name: "check"}))
+
+	assert.Len(t, all.Events("event"), 1)
+	assert.Len(t, all.ServiceChecks("check"), 1)
+	assert.Empty(t, checks.Events("event"))
+	assert.Len(t, checks.ServiceChecks("check"), 1)
+	assert.Len(t, aggregated.Events("event"), 1, "events are not aggregated")
+	assert.Len(t, aggregated.ServiceChecks("check"), 1)
+}
+
+func TestForwardingBackend_events(t *testing.T) {
+	var types []string
+	handler := func(_ context.Context, mType string, _ string, _ interface{}, _ []string, _ float64) error {
+		types = append(types, mType)
+		return nil
+	}
+
+	// Existing handlers don't receive events unless opted in
+	_, ok := NewForwardingBackend(handler).(EventSender)
+	assert.False(t, ok)
+	_, ok = NewForwardingBackend(handler).(ServiceCheckSender)
+	assert.False(t, ok)
+
+	b := NewEventForwardingBackend(handler)
+	require.NoError(t, b.Count(context.Background(), "count", 1, nil, 1))
+	require.NoError(t, b.(EventSender).Event(context.Background(), Event{Title: "deploy"}))
+	require.NoError(t, b.(ServiceCheckSender).ServiceCheck(context.Background(), ServiceCheck{Name: "check"}))
+	assert.Equal(t, []string{CountType, EventType, ServiceCheckType}, types)
+}
+
+func TestLogBackend_events(t *testing.T) {
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+
+	logging := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(logging)
+	logrus.SetLevel(logrus.DebugLevel)
+
+	b := NewLogBackend("goose.", nil)
+	require.NoError(t, b.(EventSender).Event(context.Background(), Event{Title: "deploy"}))
+	assert.Contains(t, logging.String(), "metric=goose.deploy")
+	assert.Contains(t, logging.String(), "type=event")
+}
+
+func TestDatadogBackend_events(t *testing.T) {
+	defer restoreStatsdTagsValue()(os.Getenv(StatsdDefaultTags))
+	require.NoError(t, os.Setenv(StatsdDefaultTags, ""))
+
+	conn := listenUDP(t)
+	b, err := NewDatadogBackend(conn.LocalAddr().String(), "", nil)
+	require.NoError(t, err)
+	defer FlushAndClose(b)
+
+	require.NoError(t, b.(EventSender).Event(context.Background(), Event{
+		Title:          "deploy",
+		Text:           "deployed",
+		AggregationKey: "app",
+		Priority:       EventPriorityLow,
+		AlertType:      EventAlertTypeWarning,
+		Tags:           []string{"env:test"},
+	}))
+	require.NoError(t, b.(Flusher).Flush())
+	assert.Equal(t, "_e{6,8}:deploy|deployed|k:app|p:low|t:warning|#env:test\n", readPacket(t, conn))
+
+	require.NoError(t, b.(ServiceCheckSender).ServiceCheck(context.Background(), ServiceCheck{
+		Name:    "db.reachable",
+		Status:  ServiceCheckWarn,
+		Message: "slow",
+	}))
+	require.NoError(t, b.(Flusher).Flush())
+	assert.Equal(t, "_sc|db.reachable|1|m:slow\n", readPacket(t, conn))
+}
@@ -0,0 +1,77 @@
+package statsd
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shopify/goose/logger"
+)
+
+// Exemplar is a slow observation recorded by a Timer or SLOTimer, linking the metric to the request that caused it.
+type Exemplar struct {
+	Name      string
+	Duration  time.Duration
+	Tags      []string
+	RequestID string // The logger.UUIDKey field of the Context, as set by logger.WithUUID. Empty if unset.
+	TraceID   string // The logger.TraceIDKey field of the Context, as set by the trace package. Empty if unset.
+}
+
+// ExemplarSink receives the Exemplars of slow observations.
+type ExemplarSink interface {
+	RecordExemplar(ctx context.Context, e Exemplar)
+}
+
+// ExemplarSinkFunc is a function implementing ExemplarSink.
+type ExemplarSinkFunc func(ctx context.Context, e Exemplar)
+
+func (f ExemplarSinkFunc) RecordExemplar(ctx context.Context, e Exemplar) {
+	f(ctx, e)
+}
+
+var (
+	exemplarThreshold time.Duration
+	exemplarSink      ExemplarSink = NewLogExemplarSink()
+)
+
+// SetExemplarThreshold sets the duration above which Timer and SLOTimer observations are sent to the ExemplarSink.
+// 0 disables exemplars, which is the default.
+// It should be called once at application startup, along with SetBackend.
+func SetExemplarThreshold(threshold time.Duration) {
+	exemplarThreshold = threshold
+}
+
+// SetExemplarSink sets the ExemplarSink receiving slow observations. Defaults to NewLogExemplarSink.
+// It should be called once at application startup, along with SetBackend.
+func SetExemplarSink(s ExemplarSink) {
+	exemplarSink = s
+}
+
+// NewLogExemplarSink creates an ExemplarSink logging Exemplars at the debug level.
+// The log entry contains the fields of the Context, including the request UUID, to be correlated with other log lines.
+func NewLogExemplarSink() ExemplarSink {
+	return ExemplarSinkFunc(func(ctx context.Context, e Exemplar) {
+		log(ctx, nil).
+			WithField("metric", e.Name).
+			WithField("duration", e.Duration).
+			WithField("tags", e.Tags).
+			Debug("slow metric observation")
+	})
+}
+
+// recordExemplar sends the observation to the ExemplarSink if it exceeds the exemplar threshold.
+func recordExemplar(ctx context.Context, name string, n time.Duration, tags []string) {
+	if exemplarThreshold <= 0 || n < exemplarThreshold || exemplarSink == nil {
+		return
+	}
+
+	fields := logger.GetLoggableValues(ctx)
+	requestID, _ := fields[logger.UUIDKey].(string)
+	traceID, _ := fields[logger.TraceIDKey].(string)
+	exemplarSink.RecordExemplar(ctx, Exemplar{
+		Name:      name,
+		Duration:  n,
+		Tags:      tags,
+		RequestID: requestID,
+		TraceID:   traceID,
+	})
+}
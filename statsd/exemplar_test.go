@@ -0,0 +1,75 @@
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/logger"
+)
+
+func TestExemplars(t *testing.T) {
+	WithTestBackend(t)
+	defer SetExemplarThreshold(0)
+	defer SetExemplarSink(NewLogExemplarSink())
+
+	var exemplars []Exemplar
+	SetExemplarSink(ExemplarSinkFunc(func(_ context.Context, e Exemplar) {
+		exemplars = append(exemplars, e)
+	}))
+
+	ctx, requestID := logger.WithUUID(context.Background())
+	ctx = logger.WithField(ctx, logger.TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736")
+	timer := &Timer{Name: "timer"}
+
+	// Disabled by default
+	timer.Duration(ctx, time.Hour)
+	assert.Empty(t, exemplars)
+
+	SetExemplarThreshold(time.Second)
+	timer.Duration(ctx, time.Millisecond)
+	assert.Empty(t, exemplars)
+
+	timer.Duration(ctx, 2*time.Second, Tags{"route": "/slow"})
+	require.Len(t, exemplars, 1)
+	assert.Equal(t, Exemplar{Name: "timer", Duration: 2 * time.Second, Tags: []string{"route:/slow"}, RequestID: requestID, TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"}, exemplars[0])
+
+	slo := &SLOTimer{Name: "slo", Thresholds: []time.Duration{time.Second}}
+	slo.Duration(context.Background(), time.Minute)
+	require.Len(t, exemplars, 2)
+	assert.Equal(t, "slo", exemplars[1].Name)
+	assert.Empty(t, exemplars[1].RequestID)
+
+	func() (err error) {
+		defer timer.StartTimer(ctx).SuccessFinish(&err)
+		SetExemplarThreshold(time.Nanosecond)
+		return errors.New("failed")
+	}()
+	require.Len(t, exemplars, 3)
+	assert.Equal(t, []string{"success:false"}, exemplars[2].Tags)
+}
+
+func TestLogExemplarSink(t *testing.T) {
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+
+	logging := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(logging)
+	logrus.SetLevel(logrus.DebugLevel)
+
+	ctx, requestID := logger.WithUUID(context.Background())
+	ctx = logger.WithField(ctx, logger.TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736")
+	NewLogExemplarSink().RecordExemplar(ctx, Exemplar{Name: "timer", Duration: time.Second, RequestID: requestID})
+
+	assert.Contains(t, logging.String(), "slow metric observation")
+	assert.Contains(t, logging.String(), "metric=timer")
+	assert.Contains(t, logging.String(), "uuid="+requestID)
+}
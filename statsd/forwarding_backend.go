@@ -5,6 +5,18 @@ import (
 	"time"
 )
 
+// Metric types, as passed to a ForwardHandler.
+const (
+	GaugeType        = "gauge"
+	CountType        = "count"
+	HistogramType    = "histogram"
+	DistributionType = "distribution"
+	SetType          = "set"
+	TimingType       = "timing"
+)
+
+// ForwardHandler receives every metric. If passed to NewEventForwardingBackend, it also receives events and service
+// checks, see EventType and ServiceCheckType.
 type ForwardHandler func(ctx context.Context, mType string, name string, value interface{}, tags []string, rate float64) error
 
 // NewForwardingBackend creates a new Backend that sends all metrics to a ForwardHandler
@@ -19,25 +31,46 @@ type forwardingBackend struct {
 }
 
 func (b *forwardingBackend) Gauge(ctx context.Context, name string, value float64, tags []string, rate float64) error {
-	return b.handler(ctx, "gauge", name, value, tags, rate)
+	return b.handler(ctx, GaugeType, name, value, tags, rate)
 }
 
 func (b *forwardingBackend) Count(ctx context.Context, name string, value int64, tags []string, rate float64) error {
-	return b.handler(ctx, "count", name, value, tags, rate)
+	return b.handler(ctx, CountType, name, value, tags, rate)
 }
 
 func (b *forwardingBackend) Histogram(ctx context.Context, name string, value float64, tags []string, rate float64) error {
-	return b.handler(ctx, "histogram", name, value, tags, rate)
+	return b.handler(ctx, HistogramType, name, value, tags, rate)
 }
 
 func (b *forwardingBackend) Distribution(ctx context.Context, name string, value float64, tags []string, rate float64) error {
-	return b.handler(ctx, "distribution", name, value, tags, rate)
+	return b.handler(ctx, DistributionType, name, value, tags, rate)
 }
 
 func (b *forwardingBackend) Set(ctx context.Context, name string, value string, tags []string, rate float64) error {
-	return b.handler(ctx, "set", name, value, tags, rate)
+	return b.handler(ctx, SetType, name, value, tags, rate)
 }
 
 func (b *forwardingBackend) Timing(ctx context.Context, name string, value time.Duration, tags []string, rate float64) error {
-	return b.handler(ctx, "timing", name, value, tags, rate)
+	return b.handler(ctx, TimingType, name, value, tags, rate)
+}
+
+// NewEventForwardingBackend creates a new Backend that sends all metrics, events and service checks to a
+// ForwardHandler. Unlike with NewForwardingBackend, the handler must accept the EventType and ServiceCheckType,
+// with an Event or a ServiceCheck value respectively.
+func NewEventForwardingBackend(handler ForwardHandler) Backend {
+	return &eventForwardingBackend{
+		forwardingBackend: forwardingBackend{handler: handler},
+	}
+}
+
+type eventForwardingBackend struct {
+	forwardingBackend
+}
+
+func (b *eventForwardingBackend) Event(ctx context.Context, e Event) error {
+	return b.handler(ctx, EventType, e.Title, e, e.Tags, 1)
+}
+
+func (b *eventForwardingBackend) ServiceCheck(ctx context.Context, sc ServiceCheck) error {
+	return b.handler(ctx, ServiceCheckType, sc.Name, sc, sc.Tags, 1)
 }
@@ -0,0 +1,90 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// gaugeFuncs holds the callbacks registered with RegisterGaugeFunc, by registration order.
+var gaugeFuncs = struct {
+	sync.Mutex
+	next  int
+	funcs map[int]*gaugeFunc
+}{
+	funcs: map[int]*gaugeFunc{},
+}
+
+type gaugeFunc struct {
+	gaugor *Gaugor
+	tags   Tags
+	fn     func() float64
+}
+
+// RegisterGaugeFunc registers a callback returning the current value of a gauge.
+// Registered callbacks are called, and their values recorded, by SampleGaugeFuncs.
+// genmain.NewGaugeCollectorComponent does so periodically.
+//
+// This avoids writing ad-hoc ticker loops for values which are always available, like a queue length:
+//
+//	statsd.RegisterGaugeFunc("jobs.running", nil, func() float64 {
+//		return float64(limiter.Running())
+//	})
+//
+// The returned function unregisters the callback.
+func RegisterGaugeFunc(name string, tags Tags, fn func() float64) (unregister func()) {
+	gaugeFuncs.Lock()
+	defer gaugeFuncs.Unlock()
+
+	id := gaugeFuncs.next
+	gaugeFuncs.next++
+	gaugeFuncs.funcs[id] = &gaugeFunc{
+		gaugor: &Gaugor{Name: name},
+		tags:   tags,
+		fn:     fn,
+	}
+
+	return func() {
+		gaugeFuncs.Lock()
+		defer gaugeFuncs.Unlock()
+		delete(gaugeFuncs.funcs, id)
+	}
+}
+
+// SampleGaugeFuncs calls all the callbacks registered with RegisterGaugeFunc, and records their values.
+// A callback which panics is logged and its gauge skipped, the other gauges are still recorded.
+func SampleGaugeFuncs(ctx context.Context) {
+	gaugeFuncs.Lock()
+	ids := make([]int, 0, len(gaugeFuncs.funcs))
+	for id := range gaugeFuncs.funcs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	funcs := make([]*gaugeFunc, len(ids))
+	for i, id := range ids {
+		funcs[i] = gaugeFuncs.funcs[id]
+	}
+	gaugeFuncs.Unlock()
+
+	// Callbacks are called without holding the lock, such that they may register or unregister callbacks.
+	for _, f := range funcs {
+		if value, ok := f.sample(ctx); ok {
+			f.gaugor.Gauge(ctx, value, f.tags)
+		}
+	}
+}
+
+// sample calls the callback, recovering from its panic, if any.
+func (f *gaugeFunc) sample(ctx context.Context) (value float64, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("panic: %v", r)
+			log(ctx, err).
+				WithField("metric", f.gaugor.Name).
+				Error("gauge callback panicked, skipping the gauge")
+			ok = false
+		}
+	}()
+	return f.fn(), true
+}
@@ -0,0 +1,71 @@
+package statsd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterGaugeFunc(t *testing.T) {
+	recorder := WithTestBackend(t)
+	ctx := WithTag(context.Background(), "ctx", "tag")
+
+	value := 1.0
+	unregister := RegisterGaugeFunc("gauge.func", Tags{"queue": "jobs"}, func() float64 {
+		return value
+	})
+	defer unregister()
+
+	SampleGaugeFuncs(ctx)
+	value = 2
+	SampleGaugeFuncs(ctx)
+	assert.Equal(t, []float64{1, 2}, recorder.Gauges("gauge.func", "queue:jobs", "ctx:tag"))
+
+	unregister()
+	recorder.Reset()
+	SampleGaugeFuncs(ctx)
+	assert.Empty(t, recorder.Gauges("gauge.func"))
+}
+
+func TestSampleGaugeFuncs_panic(t *testing.T) {
+	recorder := WithTestBackend(t)
+
+	defer RegisterGaugeFunc("gauge.panics", nil, func() float64 {
+		panic("boom")
+	})()
+	defer RegisterGaugeFunc("gauge.ok", nil, func() float64 {
+		return 1
+	})()
+
+	require.NotPanics(t, func() {
+		SampleGaugeFuncs(context.Background())
+	})
+	assert.Empty(t, recorder.Gauges("gauge.panics"))
+	assert.Equal(t, []float64{1}, recorder.Gauges("gauge.ok"))
+}
+
+func TestRegisterRuntimeGauges(t *testing.T) {
+	recorder := WithTestBackend(t)
+
+	RegisterRuntimeGauges()
+	RegisterRuntimeGauges()
+	SampleGaugeFuncs(context.Background())
+
+	goroutines := recorder.Gauges("runtime.goroutines")
+	require.Len(t, goroutines, 1)
+	assert.Positive(t, goroutines[0])
+
+	heap := recorder.Gauges("runtime.heap.objects")
+	require.Len(t, heap, 1)
+	assert.Positive(t, heap[0])
+
+	assert.Len(t, recorder.Gauges("runtime.heap.goal"), 1)
+	assert.Len(t, recorder.Gauges("runtime.gc.pause.last"), 1)
+	assert.Len(t, recorder.Gauges("runtime.gc.pause.total"), 1)
+
+	def, ok := DefaultRegistry.Lookup("runtime.goroutines")
+	require.True(t, ok)
+	assert.Equal(t, GaugeType, def.Type)
+}
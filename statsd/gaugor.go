@@ -10,6 +10,6 @@ type Gaugor collector
 //
 // The last parameter is an arbitrary array of tags as maps.
 func (g *Gaugor) Gauge(ctx context.Context, n float64, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Gauge(ctx, g.Name, n, tags, g.Rate.Rate()))
+	tags := metricTags(ctx, g.Name, ts...)
+	warnIfError(ctx, GetBackend().Gauge(ctx, g.Name, n, tags, g.Rate.Rate()))
 }
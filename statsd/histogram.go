@@ -8,6 +8,6 @@ type Histogram collector
 
 // The last parameter is an arbitrary array of tags as maps.
 func (m *Histogram) Histogram(ctx context.Context, n float64, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Histogram(ctx, m.Name, n, tags, m.Rate.Rate()))
+	tags := metricTags(ctx, m.Name, ts...)
+	warnIfError(ctx, GetBackend().Histogram(ctx, m.Name, n, tags, m.Rate.Rate()))
 }
@@ -18,7 +18,7 @@ func NewLogBackend(namespace string, tags []string) Backend {
 		namespace: namespace,
 		tags:      defaultTags,
 	}
-	return NewForwardingBackend(lb.log)
+	return NewEventForwardingBackend(lb.log)
 }
 
 type logBackend struct {
@@ -0,0 +1,212 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// NewMultiBackend creates a Backend that sends every metric to all the given backends.
+// All backends are called, even if some fail, and their errors are joined.
+//
+// Combined with NewFilteredBackend, it allows a progressive migration from a metrics system to another.
+func NewMultiBackend(backends ...Backend) Backend {
+	return &multiBackend{
+		backends: backends,
+	}
+}
+
+type multiBackend struct {
+	backends []Backend
+}
+
+func (b *multiBackend) each(fn func(b Backend) error) error {
+	var errs []error
+	for _, backend := range b.backends {
+		if err := fn(backend); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *multiBackend) Gauge(ctx context.Context, name string, value float64, tags []string, rate float64) error {
+	return b.each(func(b Backend) error {
+		return b.Gauge(ctx, name, value, tags, rate)
+	})
+}
+
+func (b *multiBackend) Count(ctx context.Context, name string, value int64, tags []string, rate float64) error {
+	return b.each(func(b Backend) error {
+		return b.Count(ctx, name, value, tags, rate)
+	})
+}
+
+func (b *multiBackend) Histogram(ctx context.Context, name string, value float64, tags []string, rate float64) error {
+	return b.each(func(b Backend) error {
+		return b.Histogram(ctx, name, value, tags, rate)
+	})
+}
+
+func (b *multiBackend) Distribution(ctx context.Context, name string, value float64, tags []string, rate float64) error {
+	return b.each(func(b Backend) error {
+		return b.Distribution(ctx, name, value, tags, rate)
+	})
+}
+
+func (b *multiBackend) Set(ctx context.Context, name string, value string, tags []string, rate float64) error {
+	return b.each(func(b Backend) error {
+		return b.Set(ctx, name, value, tags, rate)
+	})
+}
+
+func (b *multiBackend) Timing(ctx context.Context, name string, value time.Duration, tags []string, rate float64) error {
+	return b.each(func(b Backend) error {
+		return b.Timing(ctx, name, value, tags, rate)
+	})
+}
+
+// Event sends the Event to all the backends implementing EventSender.
+func (b *multiBackend) Event(ctx context.Context, e Event) error {
+	return b.each(func(b Backend) error {
+		if s, ok := b.(EventSender); ok {
+			return s.Event(ctx, e)
+		}
+		return nil
+	})
+}
+
+// ServiceCheck sends the ServiceCheck to all the backends implementing ServiceCheckSender.
+func (b *multiBackend) ServiceCheck(ctx context.Context, sc ServiceCheck) error {
+	return b.each(func(b Backend) error {
+		if s, ok := b.(ServiceCheckSender); ok {
+			return s.ServiceCheck(ctx, sc)
+		}
+		return nil
+	})
+}
+
+// Flush flushes all the backends implementing Flusher.
+func (b *multiBackend) Flush() error {
+	return b.each(flush)
+}
+
+// Close flushes and closes all the backends implementing Flusher and Closer.
+func (b *multiBackend) Close() error {
+	return b.each(FlushAndClose)
+}
+
+// Filter decides whether a metric, identified by its type (GaugeType, CountType, etc.) and name, should be sent.
+// Events and service checks are filtered too, with EventType and ServiceCheckType.
+type Filter func(mType string, name string) bool
+
+// NamePrefixFilter accepts the metrics whose name starts with one of the given prefixes.
+func NamePrefixFilter(prefixes ...string) Filter {
+	return func(_ string, name string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MetricTypeFilter accepts the metrics of the given types (GaugeType, CountType, etc.).
+func MetricTypeFilter(types ...string) Filter {
+	return func(mType string, _ string) bool {
+		for _, t := range types {
+			if mType == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NewFilteredBackend creates a Backend that only forwards to b the metrics accepted by filter.
+// The other metrics are silently dropped.
+func NewFilteredBackend(b Backend, filter Filter) Backend {
+	return &filteredBackend{
+		backend: b,
+		filter:  filter,
+	}
+}
+
+type filteredBackend struct {
+	backend Backend
+	filter  Filter
+}
+
+func (b *filteredBackend) Gauge(ctx context.Context, name string, value float64, tags []string, rate float64) error {
+	if !b.filter(GaugeType, name) {
+		return nil
+	}
+	return b.backend.Gauge(ctx, name, value, tags, rate)
+}
+
+func (b *filteredBackend) Count(ctx context.Context, name string, value int64, tags []string, rate float64) error {
+	if !b.filter(CountType, name) {
+		return nil
+	}
+	return b.backend.Count(ctx, name, value, tags, rate)
+}
+
+func (b *filteredBackend) Histogram(ctx context.Context, name string, value float64, tags []string, rate float64) error {
+	if !b.filter(HistogramType, name) {
+		return nil
+	}
+	return b.backend.Histogram(ctx, name, value, tags, rate)
+}
+
+func (b *filteredBackend) Distribution(ctx context.Context, name string, value float64, tags []string, rate float64) error {
+	if !b.filter(DistributionType, name) {
+		return nil
+	}
+	return b.backend.Distribution(ctx, name, value, tags, rate)
+}
+
+func (b *filteredBackend) Set(ctx context.Context, name string, value string, tags []string, rate float64) error {
+	if !b.filter(SetType, name) {
+		return nil
+	}
+	return b.backend.Set(ctx, name, value, tags, rate)
+}
+
+func (b *filteredBackend) Timing(ctx context.Context, name string, value time.Duration, tags []string, rate float64) error {
+	if !b.filter(TimingType, name) {
+		return nil
+	}
+	return b.backend.Timing(ctx, name, value, tags, rate)
+}
+
+// Event sends the Event to the wrapped backend if it implements EventSender, and the filter accepts
+// EventType and the event title.
+func (b *filteredBackend) Event(ctx context.Context, e Event) error {
+	s, ok := b.backend.(EventSender)
+	if !ok || !b.filter(EventType, e.Title) {
+		return nil
+	}
+	return s.Event(ctx, e)
+}
+
+// ServiceCheck sends the ServiceCheck to the wrapped backend if it implements ServiceCheckSender, and the filter
+// accepts ServiceCheckType and the service check name.
+func (b *filteredBackend) ServiceCheck(ctx context.Context, sc ServiceCheck) error {
+	s, ok := b.backend.(ServiceCheckSender)
+	if !ok || !b.filter(ServiceCheckType, sc.Name) {
+		return nil
+	}
+	return s.ServiceCheck(ctx, sc)
+}
+
+// Flush flushes the wrapped backend, if it implements Flusher.
+func (b *filteredBackend) Flush() error {
+	return flush(b.backend)
+}
+
+// Close flushes and closes the wrapped backend, if it implements Flusher and Closer.
+func (b *filteredBackend) Close() error {
+	return FlushAndClose(b.backend)
+}
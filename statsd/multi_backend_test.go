@@ -0,0 +1,96 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiBackend(t *testing.T) {
+	ctx := context.Background()
+	b1 := NewRecordingBackend()
+	b2 := NewRecordingBackend()
+	b := NewMultiBackend(b1, b2)
+
+	assert.NoError(t, b.Gauge(ctx, "gauge", 1, nil, 1))
+	assert.NoError(t, b.Count(ctx, "count", 2, nil, 1))
+	assert.NoError(t, b.Histogram(ctx, "histogram", 3, nil, 1))
+	assert.NoError(t, b.Distribution(ctx, "distribution", 4, nil, 1))
+	assert.NoError(t, b.Set(ctx, "set", "5", nil, 1))
+	assert.NoError(t, b.Timing(ctx, "timing", 6, nil, 1))
+
+	for _, r := range []*RecordingBackend{b1, b2} {
+		assert.Equal(t, []float64{1}, r.Gauges("gauge"))
+		assert.Equal(t, int64(2), r.CountTotal("count"))
+		assert.Equal(t, []float64{3}, r.Histograms("histogram"))
+		assert.Equal(t, []float64{4}, r.Distributions("distribution"))
+		assert.Equal(t, []string{"5"}, r.Sets("set"))
+		assert.Equal(t, []time.Duration{6}, r.Timings("timing"))
+	}
+}
+
+func TestMultiBackend_errors(t *testing.T) {
+	err1 := errors.New("err1")
+	err2 := errors.New("err2")
+	failing := func(err error) Backend {
+		return NewForwardingBackend(func(_ context.Context, _ string, _ string, _ interface{}, _ []string, _ float64) error {
+			return err
+		})
+	}
+
+	recorder := NewRecordingBackend()
+	b := NewMultiBackend(failing(err1), recorder, failing(err2))
+
+	err := b.Count(context.Background(), "count", 1, nil, 1)
+	assert.EqualError(t, err, "err1\nerr2")
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+	// Errors don't prevent other backends from receiving the metric
+	assert.Equal(t, int64(1), recorder.CountTotal("count"))
+}
+
+func TestFilteredBackend(t *testing.T) {
+	ctx := context.Background()
+	all := NewRecordingBackend()
+	prefixed := NewRecordingBackend()
+	counts := NewRecordingBackend()
+
+	b := NewMultiBackend(
+		all,
+		NewFilteredBackend(prefixed, NamePrefixFilter("http.", "shell.")),
+		NewFilteredBackend(counts, MetricTypeFilter(CountType)),
+	)
+
+	assert.NoError(t, b.Count(ctx, "http.requests", 1, nil, 1))
+	assert.NoError(t, b.Count(ctx, "db.queries", 1, nil, 1))
+	assert.NoError(t, b.Distribution(ctx, "shell.command.run", 1, nil, 1))
+	assert.NoError(t, b.Gauge(ctx, "db.connections", 1, nil, 1))
+
+	assert.Len(t, all.Metrics(), 4)
+
+	assert.Equal(t, int64(1), prefixed.CountTotal("http.requests"))
+	assert.Equal(t, []float64{1}, prefixed.Distributions("shell.command.run"))
+	assert.Len(t, prefixed.Metrics(), 2)
+
+	assert.Equal(t, int64(1), counts.CountTotal("http.requests"))
+	assert.Equal(t, int64(1), counts.CountTotal("db.queries"))
+	assert.Len(t, counts.Metrics(), 2)
+}
+
+func TestMultiBackend_flushAndClose(t *testing.T) {
+	b1 := &closingBackend{RecordingBackend: NewRecordingBackend()}
+	b2 := &closingBackend{RecordingBackend: NewRecordingBackend()}
+	b := NewMultiBackend(b1, NewFilteredBackend(b2, MetricTypeFilter(CountType)), NewNullBackend())
+
+	require.NoError(t, b.(Flusher).Flush())
+	require.Equal(t, 1, b1.flushed)
+	require.Equal(t, 1, b2.flushed)
+
+	require.Error(t, b.(Closer).Close())
+	require.Equal(t, 1, b1.closed)
+	require.Equal(t, 1, b2.closed)
+}
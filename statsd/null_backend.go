@@ -6,7 +6,7 @@ import (
 
 // NewNullBackend returns a new backend that no-ops every metric.
 func NewNullBackend() Backend {
-	return NewForwardingBackend(func(_ context.Context, _ string, _ string, _ interface{}, _ []string, _ float64) error {
+	return NewEventForwardingBackend(func(_ context.Context, _ string, _ string, _ interface{}, _ []string, _ float64) error {
 		return nil
 	})
 }
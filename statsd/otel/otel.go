@@ -0,0 +1,199 @@
+// Package otel provides a statsd.Backend recording metrics with the OpenTelemetry metrics API.
+//
+// This allows the existing statsd instrumentation to feed OpenTelemetry pipelines without modifying call sites:
+//
+//	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+//	statsd.SetBackend(otel.NewBackend(provider, "myapp"))
+//
+// It is a separate module, requiring a newer Go version than goose, such that importing goose doesn't pull in
+// OpenTelemetry.
+package otel
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Shopify/goose/statsd"
+)
+
+// InstrumentationName is the name of the Meter used by the Backend.
+const InstrumentationName = "github.com/Shopify/goose/statsd"
+
+// Backend is a statsd.Backend recording metrics with OpenTelemetry instruments:
+// Count becomes an Int64Counter, Gauge an observable Float64Gauge reporting the last value of every attribute set,
+// and Histogram, Distribution and Timing become Float64Histograms. Timings are recorded in milliseconds.
+// Tags are converted to attributes: "key:value" becomes key="value", and a tag without a value becomes tag="true".
+//
+// Instruments are created the first time a metric name is recorded.
+// Set has no OpenTelemetry equivalent and is ignored.
+type Backend struct {
+	meter     metric.Meter
+	namespace string
+
+	l          sync.Mutex
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+	gauges     map[string]*gauge
+}
+
+var _ statsd.Backend = &Backend{}
+
+// gauge holds the last value of every attribute set, reported by the observable gauge callback.
+type gauge struct {
+	l      sync.Mutex
+	values map[attribute.Distinct]gaugeValue
+}
+
+type gaugeValue struct {
+	attributes attribute.Set
+	value      float64
+}
+
+// NewBackend creates a new Backend recording metrics with a Meter of the given MeterProvider.
+//
+// `namespace` is an optional prefix to be prepended to every metric name, separated by a dot.
+func NewBackend(provider metric.MeterProvider, namespace string) *Backend {
+	return &Backend{
+		meter:      provider.Meter(InstrumentationName),
+		namespace:  namespace,
+		counters:   map[string]metric.Int64Counter{},
+		histograms: map[string]metric.Float64Histogram{},
+		gauges:     map[string]*gauge{},
+	}
+}
+
+func (b *Backend) Gauge(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	g, err := b.gauge(name)
+	if err != nil {
+		return err
+	}
+
+	attrs := tagsToAttributes(tags)
+
+	g.l.Lock()
+	defer g.l.Unlock()
+	g.values[attrs.Equivalent()] = gaugeValue{attributes: attrs, value: value}
+	return nil
+}
+
+func (b *Backend) Count(ctx context.Context, name string, value int64, tags []string, _ float64) error {
+	c, err := b.counter(name)
+	if err != nil {
+		return err
+	}
+
+	attrs := tagsToAttributes(tags)
+	c.Add(ctx, value, metric.WithAttributeSet(attrs))
+	return nil
+}
+
+func (b *Backend) Histogram(ctx context.Context, name string, value float64, tags []string, _ float64) error {
+	return b.observe(ctx, name, "", value, tags)
+}
+
+func (b *Backend) Distribution(ctx context.Context, name string, value float64, tags []string, _ float64) error {
+	return b.observe(ctx, name, "", value, tags)
+}
+
+func (b *Backend) Set(_ context.Context, _ string, _ string, _ []string, _ float64) error {
+	return nil
+}
+
+func (b *Backend) Timing(ctx context.Context, name string, value time.Duration, tags []string, _ float64) error {
+	return b.observe(ctx, name, "ms", value.Seconds()*1000, tags)
+}
+
+func (b *Backend) observe(ctx context.Context, name string, unit string, value float64, tags []string) error {
+	h, err := b.histogram(name, unit)
+	if err != nil {
+		return err
+	}
+
+	attrs := tagsToAttributes(tags)
+	h.Record(ctx, value, metric.WithAttributeSet(attrs))
+	return nil
+}
+
+func (b *Backend) counter(name string) (metric.Int64Counter, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if c, ok := b.counters[name]; ok {
+		return c, nil
+	}
+	c, err := b.meter.Int64Counter(b.metricName(name))
+	if err != nil {
+		return nil, err
+	}
+	b.counters[name] = c
+	return c, nil
+}
+
+func (b *Backend) histogram(name string, unit string) (metric.Float64Histogram, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if h, ok := b.histograms[name]; ok {
+		return h, nil
+	}
+	var opts []metric.Float64HistogramOption
+	if unit != "" {
+		opts = append(opts, metric.WithUnit(unit))
+	}
+	h, err := b.meter.Float64Histogram(b.metricName(name), opts...)
+	if err != nil {
+		return nil, err
+	}
+	b.histograms[name] = h
+	return h, nil
+}
+
+func (b *Backend) gauge(name string) (*gauge, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if g, ok := b.gauges[name]; ok {
+		return g, nil
+	}
+	g := &gauge{values: map[attribute.Distinct]gaugeValue{}}
+	_, err := b.meter.Float64ObservableGauge(b.metricName(name), metric.WithFloat64Callback(g.observe))
+	if err != nil {
+		return nil, err
+	}
+	b.gauges[name] = g
+	return g, nil
+}
+
+func (g *gauge) observe(_ context.Context, o metric.Float64Observer) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	for _, v := range g.values {
+		o.Observe(v.value, metric.WithAttributeSet(v.attributes))
+	}
+	return nil
+}
+
+func (b *Backend) metricName(name string) string {
+	if b.namespace == "" {
+		return name
+	}
+	return b.namespace + "." + name
+}
+
+func tagsToAttributes(tags []string) attribute.Set {
+	kvs := make([]attribute.KeyValue, 0, len(tags))
+	for _, tag := range tags {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok {
+			value = "true"
+		}
+		kvs = append(kvs, attribute.String(key, value))
+	}
+	return attribute.NewSet(kvs...)
+}
@@ -0,0 +1,111 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/Shopify/goose/statsd"
+	"github.com/Shopify/goose/statsd/otel"
+)
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	t.Helper()
+
+	rm := metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, otel.InstrumentationName, rm.ScopeMetrics[0].Scope.Name)
+
+	metrics := map[string]metricdata.Metrics{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+	return metrics
+}
+
+func TestBackend(t *testing.T) {
+	defer statsd.SetBackend(statsd.NewNullBackend())
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	statsd.SetBackend(otel.NewBackend(provider, "goose"))
+
+	ctx := statsd.WithTag(context.Background(), "route", "/hello")
+
+	requests := &statsd.Counter{Name: "http.requests"}
+	requests.Incr(ctx, statsd.Tags{"statusCode": 200})
+	requests.Count(ctx, 2, statsd.Tags{"statusCode": 200})
+	requests.Incr(ctx, statsd.Tags{"statusCode": 500})
+
+	inflight := &statsd.Gaugor{Name: "http.inflight"}
+	inflight.Gauge(ctx, 3)
+	inflight.Gauge(ctx, 2)
+
+	latency := &statsd.Timer{Name: "http.request"}
+	latency.Duration(ctx, 5*time.Millisecond)
+	latency.Duration(ctx, 50*time.Millisecond)
+
+	timing := &statsd.Timing{Name: "db.query"}
+	timing.Duration(ctx, time.Second, statsd.Tags{"slow": true})
+
+	(&statsd.SetCounter{Name: "users"}).CountUnique(ctx, "bob")
+
+	metrics := collect(t, reader)
+	assert.Len(t, metrics, 4)
+
+	route := attribute.String("route", "/hello")
+
+	counter := metrics["goose.http.requests"].Data.(metricdata.Sum[int64])
+	assert.True(t, counter.IsMonotonic)
+	counts := map[string]int64{}
+	for _, dp := range counter.DataPoints {
+		assert.True(t, dp.Attributes.HasValue(route.Key))
+		status, _ := dp.Attributes.Value("statusCode")
+		counts[status.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"200": 3, "500": 1}, counts)
+
+	gauge := metrics["goose.http.inflight"].Data.(metricdata.Gauge[float64])
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, 2.0, gauge.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(route), gauge.DataPoints[0].Attributes)
+
+	histogram := metrics["goose.http.request"].Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, uint64(2), histogram.DataPoints[0].Count)
+	assert.InDelta(t, 55.0, histogram.DataPoints[0].Sum, 0.001)
+
+	timings := metrics["goose.db.query"]
+	assert.Equal(t, "ms", timings.Unit)
+	dp := timings.Data.(metricdata.Histogram[float64]).DataPoints[0]
+	assert.Equal(t, 1000.0, dp.Sum)
+	assert.Equal(t, attribute.NewSet(route, attribute.String("slow", "true")), dp.Attributes)
+}
+
+func TestBackend_gaugeKeepsLastValues(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	b := otel.NewBackend(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), "")
+	ctx := context.Background()
+
+	require.NoError(t, b.Gauge(ctx, "queue.size", 1, []string{"queue:a"}, 1))
+	require.NoError(t, b.Gauge(ctx, "queue.size", 2, []string{"queue:b"}, 1))
+	collect(t, reader)
+
+	// Gauges are reported until they are updated.
+	require.NoError(t, b.Gauge(ctx, "queue.size", 3, []string{"queue:a"}, 1))
+	gauge := collect(t, reader)["queue.size"].Data.(metricdata.Gauge[float64])
+
+	values := map[string]float64{}
+	for _, dp := range gauge.DataPoints {
+		queue, _ := dp.Attributes.Value("queue")
+		values[queue.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]float64{"a": 3, "b": 2}, values)
+}
@@ -0,0 +1,377 @@
+// Package prometheus provides a statsd.Backend which aggregates metrics in memory, and a Servlet exposing them
+// in the Prometheus text exposition format.
+//
+// This allows the existing statsd instrumentation to be scraped by Prometheus without modifying call sites:
+//
+//	b := prometheus.NewBackend("myapp", nil)
+//	statsd.SetBackend(b)
+//	servlet := srvutil.CombineServlets(prometheus.NewServlet(b), ...)
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/goose/srvutil"
+	"github.com/Shopify/goose/statsd"
+)
+
+const (
+	// ContentType is the content type of the text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+
+	// bucketLabel is the label of the histogram buckets. A tag with the same name is renamed to exportedBucketLabel,
+	// like Prometheus does for conflicting labels.
+	bucketLabel         = "le"
+	exportedBucketLabel = "exported_le"
+)
+
+// DefaultBuckets are the histogram buckets used when none are specified.
+// statsd.Timer reports durations in milliseconds, so these range from 1ms to 10s.
+var DefaultBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// ErrTypeConflict is returned when a metric name is used with incompatible types.
+type ErrTypeConflict struct {
+	Name     string
+	Existing string
+	Received string
+}
+
+func (e *ErrTypeConflict) Error() string {
+	return fmt.Sprintf("metric %s is a %s, unable to record it as a %s", e.Name, e.Existing, e.Received)
+}
+
+// Backend is a statsd.Backend aggregating metrics in memory:
+// Count becomes a counter, Gauge a gauge, and Histogram, Distribution and Timing become histograms.
+// Tags are converted to labels: "key:value" becomes key="value", and a tag without a value becomes tag="true".
+//
+// Set is not supported by Prometheus and is ignored.
+type Backend struct {
+	namespace string
+	buckets   []float64
+
+	l        sync.Mutex
+	families map[string]*family
+}
+
+var _ statsd.Backend = &Backend{}
+
+type family struct {
+	name   string
+	mType  string
+	series map[string]*series
+}
+
+type series struct {
+	labels []label
+
+	value   float64
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+// NewBackend creates a new Backend.
+//
+// `namespace` is an optional prefix to be prepended to every metric name, separated by an underscore.
+// `buckets` are the upper bounds of the histogram buckets, DefaultBuckets are used if nil.
+func NewBackend(namespace string, buckets []float64) *Backend {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+
+	return &Backend{
+		namespace: namespace,
+		buckets:   buckets,
+		families:  map[string]*family{},
+	}
+}
+
+// NewServlet creates a Servlet exposing the metrics of the Backend on /metrics.
+func NewServlet(b *Backend) srvutil.Servlet {
+	return srvutil.HandlerServlet("/metrics", b)
+}
+
+func (b *Backend) Gauge(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	return b.record(name, gaugeType, tags, func(s *series) {
+		s.value = value
+	})
+}
+
+func (b *Backend) Count(_ context.Context, name string, value int64, tags []string, _ float64) error {
+	name = metricName(name)
+	if !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	return b.record(name, counterType, tags, func(s *series) {
+		s.value += float64(value)
+	})
+}
+
+func (b *Backend) Histogram(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	return b.observe(name, value, tags)
+}
+
+func (b *Backend) Distribution(_ context.Context, name string, value float64, tags []string, _ float64) error {
+	return b.observe(name, value, tags)
+}
+
+func (b *Backend) Set(_ context.Context, _ string, _ string, _ []string, _ float64) error {
+	return nil
+}
+
+func (b *Backend) Timing(_ context.Context, name string, value time.Duration, tags []string, _ float64) error {
+	return b.observe(name, value.Seconds()*1000, tags)
+}
+
+func (b *Backend) observe(name string, value float64, tags []string) error {
+	return b.record(name, histogramType, tags, func(s *series) {
+		if s.buckets == nil {
+			s.buckets = make([]uint64, len(b.buckets))
+		}
+		for i, upperBound := range b.buckets {
+			if value <= upperBound {
+				s.buckets[i]++
+			}
+		}
+		s.sum += value
+		s.count++
+	})
+}
+
+func (b *Backend) record(name string, mType string, tags []string, fn func(s *series)) error {
+	name = metricName(name)
+	if b.namespace != "" {
+		name = metricName(b.namespace) + "_" + name
+	}
+
+	labels := tagsToLabels(tags)
+	if mType == histogramType {
+		labels = renameBucketLabel(labels)
+	}
+	key := formatLabels(labels, nil)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	f, ok := b.families[name]
+	if !ok {
+		f = &family{name: name, mType: mType, series: map[string]*series{}}
+		b.families[name] = f
+	} else if f.mType != mType {
+		return &ErrTypeConflict{Name: name, Existing: f.mType, Received: mType}
+	}
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: labels}
+		f.series[key] = s
+	}
+	fn(s)
+	return nil
+}
+
+// ServeHTTP writes all metrics in the text exposition format.
+func (b *Backend) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = b.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the text exposition format.
+// Families and series are sorted to provide a stable output.
+//
+// The metrics are formatted in memory, such that a slow writer doesn't prevent recording metrics.
+func (b *Backend) WriteTo(w io.Writer) (int64, error) {
+	buf := &bytes.Buffer{}
+
+	b.l.Lock()
+	names := make([]string, 0, len(b.families))
+	for name := range b.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.writeFamily(buf, b.families[name])
+	}
+	b.l.Unlock()
+
+	return buf.WriteTo(w)
+}
+
+func (b *Backend) writeFamily(w *bytes.Buffer, f *family) {
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.mType)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.mType != histogramType {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, key, formatFloat(s.value))
+			continue
+		}
+
+		for i, upperBound := range b.buckets {
+			le := label{name: bucketLabel, value: formatFloat(upperBound)}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(s.labels, &le), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(s.labels, &label{name: bucketLabel, value: "+Inf"}), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, key, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, key, s.count)
+	}
+}
+
+// tagsToLabels converts statsd tags to labels sorted by name. When a key is repeated, the last value wins.
+func tagsToLabels(tags []string) []label {
+	byName := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		k, v := tag, "true"
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			k, v = tag[:i], tag[i+1:]
+		}
+		byName[labelName(k)] = v
+	}
+
+	labels := make([]label, 0, len(byName))
+	for k, v := range byName {
+		labels = append(labels, label{name: k, value: v})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+	return labels
+}
+
+// renameBucketLabel renames the label conflicting with the bucket label of histograms, keeping the labels sorted.
+func renameBucketLabel(labels []label) []label {
+	for i, l := range labels {
+		if l.name != bucketLabel {
+			continue
+		}
+
+		renamed := make([]label, 0, len(labels))
+		renamed = append(renamed, labels[:i]...)
+		renamed = append(renamed, labels[i+1:]...)
+		j := sort.Search(len(renamed), func(j int) bool {
+			return renamed[j].name >= exportedBucketLabel
+		})
+		if j < len(renamed) && renamed[j].name == exportedBucketLabel {
+			// An exported_le tag takes precedence, like a repeated key.
+			return renamed
+		}
+		renamed = append(renamed, label{})
+		copy(renamed[j+1:], renamed[j:])
+		renamed[j] = label{name: exportedBucketLabel, value: l.value}
+		return renamed
+	}
+	return labels
+}
+
+func formatLabels(labels []label, extra *label) string {
+	if len(labels) == 0 && extra == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		writeLabel(&sb, l)
+	}
+	if extra != nil {
+		if len(labels) > 0 {
+			sb.WriteByte(',')
+		}
+		writeLabel(&sb, *extra)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func writeLabel(sb *strings.Builder, l label) {
+	sb.WriteString(l.name)
+	sb.WriteString(`="`)
+	for _, r := range l.value {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+}
+
+// metricName replaces the characters not allowed in metric names, typically the dots used by statsd, with underscores.
+func metricName(name string) string {
+	return sanitizeName(name, true)
+}
+
+// labelName is the same as metricName, but colons are not allowed.
+func labelName(name string) string {
+	return sanitizeName(name, false)
+}
+
+func sanitizeName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+
+	var sb strings.Builder
+	for i, r := range name {
+		valid := r == '_' ||
+			(r >= 'a' && r <= 'z') ||
+			(r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9' && i > 0) ||
+			(r == ':' && allowColon)
+		if valid {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
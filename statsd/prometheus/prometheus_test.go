@@ -0,0 +1,147 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/statsd"
+	"github.com/Shopify/goose/statsd/prometheus"
+)
+
+func TestBackend(t *testing.T) {
+	defer statsd.SetBackend(statsd.NewNullBackend())
+
+	b := prometheus.NewBackend("goose", []float64{10, 100})
+	statsd.SetBackend(b)
+
+	ctx := statsd.WithTag(context.Background(), "route", "/hello/@name")
+
+	requests := &statsd.Counter{Name: "http.requests"}
+	requests.Incr(ctx, statsd.Tags{"statusCode": 200})
+	requests.Count(ctx, 2, statsd.Tags{"statusCode": 200})
+	requests.Incr(ctx, statsd.Tags{"statusCode": 500})
+
+	inflight := &statsd.Gaugor{Name: "http.inflight"}
+	inflight.Gauge(ctx, 3)
+	inflight.Gauge(ctx, 2)
+
+	latency := &statsd.Timer{Name: "http.request"}
+	latency.Duration(ctx, 5*time.Millisecond)
+	latency.Duration(ctx, 50*time.Millisecond)
+	latency.Duration(ctx, time.Second)
+
+	buf := &bytes.Buffer{}
+	n, err := b.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	assert.Equal(t, `# TYPE goose_http_inflight gauge
+goose_http_inflight{route="/hello/@name"} 2
+# TYPE goose_http_request histogram
+goose_http_request_bucket{route="/hello/@name",le="10"} 1
+goose_http_request_bucket{route="/hello/@name",le="100"} 2
+goose_http_request_bucket{route="/hello/@name",le="+Inf"} 3
+goose_http_request_sum{route="/hello/@name"} 1055
+goose_http_request_count{route="/hello/@name"} 3
+# TYPE goose_http_requests_total counter
+goose_http_requests_total{route="/hello/@name",statusCode="200"} 3
+goose_http_requests_total{route="/hello/@name",statusCode="500"} 1
+`, buf.String())
+}
+
+func TestBackend_labels(t *testing.T) {
+	ctx := context.Background()
+	b := prometheus.NewBackend("", nil)
+
+	require.NoError(t, b.Gauge(ctx, "gauge", 1, []string{"b:2", "a.key:1", "flag", "quoted:\"x\"\n", "b:3"}, 1))
+
+	buf := &bytes.Buffer{}
+	_, err := b.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE gauge gauge
+gauge{a_key="1",b="3",flag="true",quoted="\"x\"\n"} 1
+`, buf.String())
+}
+
+func TestBackend_typeConflict(t *testing.T) {
+	ctx := context.Background()
+	b := prometheus.NewBackend("", nil)
+
+	require.NoError(t, b.Gauge(ctx, "metric", 1, nil, 1))
+	err := b.Histogram(ctx, "metric", 1, nil, 1)
+	assert.EqualError(t, err, "metric metric is a gauge, unable to record it as a histogram")
+}
+
+func TestBackend_histogramBucketLabel(t *testing.T) {
+	ctx := context.Background()
+	b := prometheus.NewBackend("", []float64{1})
+
+	require.NoError(t, b.Histogram(ctx, "metric", 1, []string{"le:x", "a:b"}, 1))
+
+	buf := &bytes.Buffer{}
+	_, err := b.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE metric histogram
+metric_bucket{a="b",exported_le="x",le="1"} 1
+metric_bucket{a="b",exported_le="x",le="+Inf"} 1
+metric_sum{a="b",exported_le="x"} 1
+metric_count{a="b",exported_le="x"} 1
+`, buf.String())
+}
+
+type blockingWriter struct {
+	written chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	close(w.written)
+	<-w.release
+	return len(p), nil
+}
+
+func TestBackend_slowWriter(t *testing.T) {
+	ctx := context.Background()
+	b := prometheus.NewBackend("", nil)
+	require.NoError(t, b.Count(ctx, "metric", 1, nil, 1))
+
+	w := &blockingWriter{written: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = b.WriteTo(w)
+	}()
+	<-w.written
+
+	// Recording doesn't wait for the writer
+	require.NoError(t, b.Count(ctx, "metric", 1, nil, 1))
+
+	close(w.release)
+	<-done
+}
+
+func TestNewServlet(t *testing.T) {
+	b := prometheus.NewBackend("", nil)
+	require.NoError(t, b.Count(context.Background(), "metric", 1, nil, 1))
+
+	r := mux.NewRouter()
+	prometheus.NewServlet(b).RegisterRouting(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, prometheus.ContentType, w.Header().Get("Content-Type"))
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "# TYPE metric_total counter\nmetric_total 1\n", string(body))
+}
@@ -0,0 +1,184 @@
+package statsd
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metric is a single call recorded by a RecordingBackend.
+type Metric struct {
+	Type  string // One of GaugeType, CountType, etc.
+	Name  string
+	Value interface{} // int64 for counts, string for sets, time.Duration for timings, Event, ServiceCheck, float64 otherwise
+	Tags  []string    // Sorted
+	Rate  float64
+}
+
+// HasTags returns whether the metric was recorded with all the given tags, formatted as "key:value".
+func (m *Metric) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		i := sort.SearchStrings(m.Tags, tag)
+		if i == len(m.Tags) || m.Tags[i] != tag {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordingBackend is a Backend storing every metric in memory, meant to be used in tests.
+// See WithTestBackend.
+type RecordingBackend struct {
+	Backend
+
+	l       sync.Mutex
+	metrics []Metric
+}
+
+// NewRecordingBackend creates a new RecordingBackend.
+func NewRecordingBackend() *RecordingBackend {
+	b := &RecordingBackend{}
+	b.Backend = NewForwardingBackend(b.record)
+	return b
+}
+
+// TestingT is the subset of testing.TB used by WithTestBackend.
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+}
+
+// WithTestBackend replaces the current backend with a new RecordingBackend for the duration of the test.
+// The previous backend is restored when the test completes.
+func WithTestBackend(t TestingT) *RecordingBackend {
+	t.Helper()
+
+	b := NewRecordingBackend()
+	prev := SwapBackend(b)
+	t.Cleanup(func() { SetBackend(prev) })
+	return b
+}
+
+func (b *RecordingBackend) record(_ context.Context, mType string, name string, value interface{}, tags []string, rate float64) error {
+	sortedTags := append([]string{}, tags...)
+	sort.Strings(sortedTags)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.metrics = append(b.metrics, Metric{
+		Type:  mType,
+		Name:  name,
+		Value: value,
+		Tags:  sortedTags,
+		Rate:  rate,
+	})
+	return nil
+}
+
+// Metrics returns every recorded metric, in order.
+func (b *RecordingBackend) Metrics() []Metric {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return append([]Metric{}, b.metrics...)
+}
+
+// Find returns the metrics of the given type and name, which have all the given tags.
+func (b *RecordingBackend) Find(mType string, name string, tags ...string) []Metric {
+	var found []Metric
+	for _, m := range b.Metrics() {
+		if m.Type == mType && m.Name == name && m.HasTags(tags...) {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// CountTotal returns the sum of all counts with the given name and tags.
+func (b *RecordingBackend) CountTotal(name string, tags ...string) int64 {
+	var total int64
+	for _, m := range b.Find(CountType, name, tags...) {
+		total += m.Value.(int64)
+	}
+	return total
+}
+
+// Gauges returns the values of the gauges with the given name and tags, in order.
+func (b *RecordingBackend) Gauges(name string, tags ...string) []float64 {
+	return b.floats(GaugeType, name, tags)
+}
+
+// Histograms returns the values of the histograms with the given name and tags, in order.
+func (b *RecordingBackend) Histograms(name string, tags ...string) []float64 {
+	return b.floats(HistogramType, name, tags)
+}
+
+// Distributions returns the values of the distributions with the given name and tags, in order.
+// Timer durations are recorded as distributions, in milliseconds.
+func (b *RecordingBackend) Distributions(name string, tags ...string) []float64 {
+	return b.floats(DistributionType, name, tags)
+}
+
+// Sets returns the values of the sets with the given name and tags, in order.
+func (b *RecordingBackend) Sets(name string, tags ...string) []string {
+	var values []string
+	for _, m := range b.Find(SetType, name, tags...) {
+		values = append(values, m.Value.(string))
+	}
+	return values
+}
+
+// Timings returns the values of the timings with the given name and tags, in order.
+func (b *RecordingBackend) Timings(name string, tags ...string) []time.Duration {
+	var values []time.Duration
+	for _, m := range b.Find(TimingType, name, tags...) {
+		values = append(values, m.Value.(time.Duration))
+	}
+	return values
+}
+
+// Reset forgets all recorded metrics.
+func (b *RecordingBackend) Reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.metrics = nil
+}
+
+func (b *RecordingBackend) floats(mType string, name string, tags []string) []float64 {
+	var values []float64
+	for _, m := range b.Find(mType, name, tags...) {
+		values = append(values, m.Value.(float64))
+	}
+	return values
+}
+
+// Event records the Event as a Metric with the EventType.
+func (b *RecordingBackend) Event(ctx context.Context, e Event) error {
+	return b.record(ctx, EventType, e.Title, e, e.Tags, 1)
+}
+
+// ServiceCheck records the ServiceCheck as a Metric with the ServiceCheckType.
+func (b *RecordingBackend) ServiceCheck(ctx context.Context, sc ServiceCheck) error {
+	return b.record(ctx, ServiceCheckType, sc.Name, sc, sc.Tags, 1)
+}
+
+// Events returns the recorded events with the given title, which have all the given tags.
+func (b *RecordingBackend) Events(title string, tags ...string) []Event {
+	var events []Event
+	for _, m := range b.Find(EventType, title, tags...) {
+		events = append(events, m.Value.(Event))
+	}
+	return events
+}
+
+// ServiceChecks returns the recorded service checks with the given name, which have all the given tags.
+func (b *RecordingBackend) ServiceChecks(name string, tags ...string) []ServiceCheck {
+	var checks []ServiceCheck
+	for _, m := range b.Find(ServiceCheckType, name, tags...) {
+		checks = append(checks, m.Value.(ServiceCheck))
+	}
+	return checks
+}
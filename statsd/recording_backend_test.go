@@ -0,0 +1,73 @@
+package statsd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func TestRecordingBackend(t *testing.T) {
+	b := WithTestBackend(t)
+	ctx := WithTags(context.Background(), Tags{"b": 2, "a": 1})
+
+	(&Counter{Name: "counter"}).Count(ctx, 2)
+	(&Counter{Name: "counter"}).Incr(ctx, Tags{"c": 3})
+	(&Counter{Name: "other"}).Incr(ctx)
+	(&Gaugor{Name: "gauge"}).Gauge(ctx, 1.5)
+	(&Gaugor{Name: "gauge"}).Gauge(ctx, 2.5, Tags{"c": 3})
+	(&Histogram{Name: "histogram"}).Histogram(ctx, 3)
+	(&Distribution{Name: "distribution", Rate: 0.5}).Distribution(ctx, 4)
+	(&Timer{Name: "timer"}).Duration(ctx, time.Second)
+	(&SetCounter{Name: "set"}).CountUnique(ctx, "user")
+	(&Timing{Name: "timing"}).Duration(ctx, time.Millisecond)
+
+	assert.Equal(t, int64(3), b.CountTotal("counter"))
+	assert.Equal(t, int64(3), b.CountTotal("counter", "a:1", "b:2"))
+	assert.Equal(t, int64(1), b.CountTotal("counter", "c:3"))
+	assert.Equal(t, int64(0), b.CountTotal("counter", "d:4"))
+	assert.Equal(t, int64(1), b.CountTotal("other"))
+
+	assert.Equal(t, []float64{1.5, 2.5}, b.Gauges("gauge"))
+	assert.Equal(t, []float64{2.5}, b.Gauges("gauge", "c:3"))
+	assert.Equal(t, []float64{3}, b.Histograms("histogram"))
+	assert.Equal(t, []float64{4}, b.Distributions("distribution"))
+	assert.Equal(t, []float64{1000}, b.Distributions("timer"))
+	assert.Equal(t, []string{"user"}, b.Sets("set"))
+	assert.Equal(t, []time.Duration{time.Millisecond}, b.Timings("timing"))
+
+	assert.Equal(t, []Metric{{
+		Type:  DistributionType,
+		Name:  "distribution",
+		Value: 4.0,
+		Tags:  []string{"a:1", "b:2"},
+		Rate:  0.5,
+	}}, b.Find(DistributionType, "distribution"))
+
+	b.Reset()
+	assert.Empty(t, b.Metrics())
+}
+
+func TestWithTestBackend(t *testing.T) {
+	prev := NewNullBackend()
+	SetBackend(prev)
+
+	ft := &fakeT{}
+	b := WithTestBackend(ft)
+	assert.True(t, b == GetBackend())
+
+	assert.Len(t, ft.cleanups, 1)
+	ft.cleanups[0]()
+	assert.True(t, prev == GetBackend())
+}
@@ -0,0 +1,262 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Definition describes a metric declared in a Registry.
+type Definition struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // One of GaugeType, CountType, etc.
+	Description string   `json:"description,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	TagKeys     []string `json:"tagKeys,omitempty"` // Tag keys allowed on this metric, in addition to the backend's global tags.
+}
+
+// ErrConflictingDefinition is returned when a metric is registered twice with different types.
+type ErrConflictingDefinition struct {
+	Existing Definition
+	New      Definition
+}
+
+func (e *ErrConflictingDefinition) Error() string {
+	return fmt.Sprintf("metric %s is already registered as a %s, unable to register it as a %s", e.Existing.Name, e.Existing.Type, e.New.Type)
+}
+
+// Registry is a catalog of the metrics emitted by an application.
+// Declaring metrics in a Registry ensures that a name is not reused with a different type,
+// and warns when metrics are recorded with undeclared tag keys.
+//
+// Recorded metrics are checked against DefaultRegistry. Metrics which are not registered are not checked.
+type Registry struct {
+	l           sync.RWMutex
+	definitions map[string]*registration
+}
+
+type registration struct {
+	Definition
+	tagKeys      map[string]struct{}
+	lowerTagKeys map[string]struct{} // Used when SetLowercaseTagKeys is enabled.
+	warned       map[string]struct{}
+}
+
+// definition returns a copy of the Definition, such that further registrations don't modify it.
+func (reg *registration) definition() Definition {
+	d := reg.Definition
+	d.TagKeys = append([]string(nil), d.TagKeys...)
+	return d
+}
+
+// DefaultRegistry is the Registry checked when recording metrics.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		definitions: map[string]*registration{},
+	}
+}
+
+// Register adds a Definition to the Registry.
+// Registering the same name again with the same type is allowed, in which case tag keys are merged.
+// Registering the same name with a different type returns an ErrConflictingDefinition.
+func (r *Registry) Register(d Definition) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	existing, ok := r.definitions[d.Name]
+	if !ok {
+		existing = &registration{
+			Definition:   d,
+			tagKeys:      map[string]struct{}{},
+			lowerTagKeys: map[string]struct{}{},
+			warned:       map[string]struct{}{},
+		}
+		existing.TagKeys = nil
+		r.definitions[d.Name] = existing
+	} else if existing.Type != d.Type {
+		return &ErrConflictingDefinition{Existing: existing.definition(), New: d}
+	}
+
+	for _, k := range d.TagKeys {
+		if _, ok := existing.tagKeys[k]; !ok {
+			existing.tagKeys[k] = struct{}{}
+			existing.lowerTagKeys[strings.ToLower(k)] = struct{}{}
+			existing.TagKeys = append(existing.TagKeys, k)
+		}
+	}
+	sort.Strings(existing.TagKeys)
+	return nil
+}
+
+// MustRegister is the same as Register, but panics on error.
+// Meant to be used when declaring metrics as package variables.
+func (r *Registry) MustRegister(d Definition) {
+	if err := r.Register(d); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the Definition registered with the given name.
+func (r *Registry) Lookup(name string) (Definition, bool) {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	if reg, ok := r.definitions[name]; ok {
+		return reg.definition(), true
+	}
+	return Definition{}, false
+}
+
+// Definitions returns all registered Definitions, sorted by name.
+func (r *Registry) Definitions() []Definition {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	defs := make([]Definition, 0, len(r.definitions))
+	for _, reg := range r.definitions {
+		defs = append(defs, reg.definition())
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// Describe returns all registered Definitions as JSON, sorted by name.
+// Useful to catalog what a service emits, or to generate dashboards.
+func (r *Registry) Describe() ([]byte, error) {
+	return json.MarshalIndent(r.Definitions(), "", "  ")
+}
+
+// Counter registers a Definition with the CountType and returns the corresponding Counter.
+// It panics if the name is already registered with a different type.
+func (r *Registry) Counter(d Definition) *Counter {
+	d.Type = CountType
+	r.MustRegister(d)
+	return &Counter{Name: d.Name}
+}
+
+// Gaugor registers a Definition with the GaugeType and returns the corresponding Gaugor.
+// It panics if the name is already registered with a different type.
+func (r *Registry) Gaugor(d Definition) *Gaugor {
+	d.Type = GaugeType
+	r.MustRegister(d)
+	return &Gaugor{Name: d.Name}
+}
+
+// Histogram registers a Definition with the HistogramType and returns the corresponding Histogram.
+// It panics if the name is already registered with a different type.
+func (r *Registry) Histogram(d Definition) *Histogram {
+	d.Type = HistogramType
+	r.MustRegister(d)
+	return &Histogram{Name: d.Name}
+}
+
+// Distribution registers a Definition with the DistributionType and returns the corresponding Distribution.
+// It panics if the name is already registered with a different type.
+func (r *Registry) Distribution(d Definition) *Distribution {
+	d.Type = DistributionType
+	r.MustRegister(d)
+	return &Distribution{Name: d.Name}
+}
+
+// SetCounter registers a Definition with the SetType and returns the corresponding SetCounter.
+// It panics if the name is already registered with a different type.
+func (r *Registry) SetCounter(d Definition) *SetCounter {
+	d.Type = SetType
+	r.MustRegister(d)
+	return &SetCounter{Name: d.Name}
+}
+
+// Timer registers a Definition with the DistributionType, since this is what Timer submits, and returns the
+// corresponding Timer. The unit defaults to milliseconds.
+// It panics if the name is already registered with a different type.
+func (r *Registry) Timer(d Definition) *Timer {
+	d.Type = DistributionType
+	if d.Unit == "" {
+		d.Unit = "millisecond"
+	}
+	r.MustRegister(d)
+	return &Timer{Name: d.Name}
+}
+
+// SLOTimer registers a Definition with the DistributionType, and the corresponding `<name>.within_slo` counter
+// tagged with slo and within, then returns the SLOTimer measuring the given thresholds.
+// The unit defaults to milliseconds.
+// It panics if either name is already registered with a different type.
+func (r *Registry) SLOTimer(d Definition, thresholds ...time.Duration) *SLOTimer {
+	t := &SLOTimer{Name: r.Timer(d).Name, Thresholds: thresholds}
+	r.MustRegister(Definition{
+		Name:        t.WithinSLOName(),
+		Type:        CountType,
+		Description: fmt.Sprintf("Observations of %s within latency SLO thresholds.", d.Name),
+		TagKeys:     append([]string{"slo", "within"}, d.TagKeys...),
+	})
+	return t
+}
+
+// Timing registers a Definition with the TimingType and returns the corresponding Timing.
+// It panics if the name is already registered with a different type.
+func (r *Registry) Timing(d Definition) *Timing {
+	d.Type = TimingType
+	if d.Unit == "" {
+		d.Unit = "millisecond"
+	}
+	r.MustRegister(d)
+	return &Timing{Name: d.Name}
+}
+
+// checkTags logs a warning the first time a registered metric is recorded with an undeclared tag key.
+func (r *Registry) checkTags(ctx context.Context, name string, tags []string) {
+	r.l.RLock()
+	reg, ok := r.definitions[name]
+	if !ok {
+		r.l.RUnlock()
+		return
+	}
+
+	var undeclared []string
+	for _, tag := range tags {
+		key, _ := splitTag(tag)
+		if _, ok := reg.tagKeys[key]; ok {
+			continue
+		}
+		if _, ok := reg.lowerTagKeys[key]; ok && lowercaseTagKeys.Load() {
+			continue
+		}
+		if _, ok := reg.warned[key]; !ok {
+			undeclared = append(undeclared, key)
+		}
+	}
+	r.l.RUnlock()
+
+	if len(undeclared) == 0 {
+		return
+	}
+
+	// Another goroutine may have warned in the meantime.
+	r.l.Lock()
+	newlyWarned := undeclared[:0]
+	for _, key := range undeclared {
+		if _, ok := reg.warned[key]; !ok {
+			reg.warned[key] = struct{}{}
+			newlyWarned = append(newlyWarned, key)
+		}
+	}
+	r.l.Unlock()
+
+	if len(newlyWarned) == 0 {
+		return
+	}
+	log(ctx, nil).
+		WithField("metric", name).
+		WithField("tagKeys", newlyWarned).
+		Warn("metric recorded with undeclared tag keys")
+}
@@ -0,0 +1,79 @@
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+
+	counter := r.Counter(Definition{Name: "requests", Description: "Requests", TagKeys: []string{"b"}})
+	assert.Equal(t, "requests", counter.Name)
+
+	// Same type: tag keys are merged
+	require.NoError(t, r.Register(Definition{Name: "requests", Type: CountType, TagKeys: []string{"a", "b"}}))
+
+	def, ok := r.Lookup("requests")
+	require.True(t, ok)
+	assert.Equal(t, Definition{Name: "requests", Type: CountType, Description: "Requests", TagKeys: []string{"a", "b"}}, def)
+
+	// Different type: conflict
+	err := r.Register(Definition{Name: "requests", Type: GaugeType})
+	assert.EqualError(t, err, "metric requests is already registered as a count, unable to register it as a gauge")
+	assert.IsType(t, &ErrConflictingDefinition{}, err)
+	assert.Panics(t, func() {
+		r.Gaugor(Definition{Name: "requests"})
+	})
+
+	_, ok = r.Lookup("unknown")
+	assert.False(t, ok)
+}
+
+func TestRegistry_Describe(t *testing.T) {
+	r := NewRegistry()
+	r.Timer(Definition{Name: "b.timer", Description: "A timer", TagKeys: []string{"success"}})
+	r.Gaugor(Definition{Name: "a.gauge", Unit: "connection"})
+
+	out, err := r.Describe()
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "a.gauge", "type": "gauge", "unit": "connection"},
+		{"name": "b.timer", "type": "distribution", "description": "A timer", "unit": "millisecond", "tagKeys": ["success"]}
+	]`, string(out))
+}
+
+func TestRegistry_undeclaredTags(t *testing.T) {
+	WithTestBackend(t)
+
+	// A new registry, since the undeclared keys are only warned about once
+	defer func(r *Registry) { DefaultRegistry = r }(DefaultRegistry)
+	DefaultRegistry = NewRegistry()
+
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	logging := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(logging)
+
+	metric := DefaultRegistry.Counter(Definition{Name: "registry.test", TagKeys: []string{"declared"}})
+	ctx := WithTag(context.Background(), "declared", "ok")
+
+	metric.Incr(ctx)
+	assert.Empty(t, logging.String())
+
+	metric.Incr(ctx, Tags{"undeclared": "yes"})
+	metric.Incr(ctx, Tags{"undeclared": "again"})
+	assert.Equal(t, 1, strings.Count(logging.String(), "metric recorded with undeclared tag keys"))
+	assert.Contains(t, logging.String(), "tagKeys=\"[undeclared]\"")
+
+	// Unregistered metrics aren't checked
+	logging.Reset()
+	(&Counter{Name: "registry.unregistered"}).Incr(ctx, Tags{"anything": "goes"})
+	assert.Empty(t, logging.String())
+}
@@ -0,0 +1,82 @@
+package statsd
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+)
+
+const procSelfFD = "/proc/self/fd"
+
+var registerRuntimeGaugesOnce sync.Once
+
+// RegisterRuntimeGauges registers gauge callbacks reporting the state of the Go runtime:
+//
+//   - runtime.goroutines: the number of goroutines.
+//   - runtime.heap.objects: the bytes occupied by live and not yet collected heap objects.
+//   - runtime.heap.goal: the heap size target for the end of the next GC cycle, in bytes.
+//   - runtime.gc.pause.last and runtime.gc.pause.total: the last and cumulated GC pauses, in milliseconds.
+//   - runtime.fds.open: the number of open file descriptors, only where /proc/self/fd is available.
+//
+// It is safe to call multiple times, the callbacks are only registered once.
+func RegisterRuntimeGauges() {
+	registerRuntimeGaugesOnce.Do(func() {
+		for _, g := range []struct {
+			def Definition
+			fn  func() float64
+		}{
+			{Definition{Name: "runtime.goroutines", Description: "Number of goroutines."}, func() float64 {
+				return float64(runtime.NumGoroutine())
+			}},
+			{Definition{Name: "runtime.heap.objects", Unit: "byte", Description: "Bytes occupied by heap objects."}, func() float64 {
+				return readRuntimeMetric("/memory/classes/heap/objects:bytes")
+			}},
+			{Definition{Name: "runtime.heap.goal", Unit: "byte", Description: "Heap size target of the next GC cycle."}, func() float64 {
+				return readRuntimeMetric("/gc/heap/goal:bytes")
+			}},
+			{Definition{Name: "runtime.gc.pause.last", Unit: "millisecond", Description: "Duration of the last GC pause."}, func() float64 {
+				stats := debug.GCStats{}
+				debug.ReadGCStats(&stats)
+				if len(stats.Pause) == 0 {
+					return 0
+				}
+				return stats.Pause[0].Seconds() * 1000
+			}},
+			{Definition{Name: "runtime.gc.pause.total", Unit: "millisecond", Description: "Cumulated duration of GC pauses."}, func() float64 {
+				stats := debug.GCStats{}
+				debug.ReadGCStats(&stats)
+				return stats.PauseTotal.Seconds() * 1000
+			}},
+		} {
+			RegisterGaugeFunc(DefaultRegistry.Gaugor(g.def).Name, nil, g.fn)
+		}
+
+		if _, err := os.ReadDir(procSelfFD); err == nil {
+			def := Definition{Name: "runtime.fds.open", Description: "Number of open file descriptors."}
+			RegisterGaugeFunc(DefaultRegistry.Gaugor(def).Name, nil, func() float64 {
+				entries, err := os.ReadDir(procSelfFD)
+				if err != nil {
+					return 0
+				}
+				// Reading the directory opens a file descriptor, which is listed too.
+				return float64(len(entries) - 1)
+			})
+		}
+	})
+}
+
+func readRuntimeMetric(name string) float64 {
+	sample := []metrics.Sample{{Name: name}}
+	metrics.Read(sample)
+
+	switch sample[0].Value.Kind() {
+	case metrics.KindUint64:
+		return float64(sample[0].Value.Uint64())
+	case metrics.KindFloat64:
+		return sample[0].Value.Float64()
+	default:
+		return 0
+	}
+}
@@ -10,6 +10,6 @@ type SetCounter collector
 //
 // The last parameter is an arbitrary array of tags as maps.
 func (c *SetCounter) CountUnique(ctx context.Context, value string, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Set(ctx, c.Name, value, tags, c.Rate.Rate()))
+	tags := metricTags(ctx, c.Name, ts...)
+	warnIfError(ctx, GetBackend().Set(ctx, c.Name, value, tags, c.Rate.Rate()))
 }
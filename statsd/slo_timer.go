@@ -0,0 +1,92 @@
+package statsd
+
+import (
+	"context"
+	"time"
+)
+
+// SLOTimer is a Timer which also counts how many observations are within latency SLO thresholds,
+// and raises the sample rate of observations which matter most.
+//
+// Every observation is submitted as a distribution, like Timer, which provides percentile summaries.
+// In addition, for every threshold, a `<name>.within_slo` counter is incremented, tagged with the threshold as
+// `slo:100ms` and whether the observation was within it as `within:true` or `within:false`.
+//
+// Observations are sampled with Rate, except those which are failures (tagged with `success:false`) or slow
+// (at least SlowThreshold, or above the largest threshold by default): these are always submitted,
+// such that rare errors aren't sampled away.
+type SLOTimer struct {
+	Name          string
+	Rate          sampleRate      // 0 (default value) is interpreted as 100% (1.0)
+	Thresholds    []time.Duration // Latency SLOs, such as 100ms, 500ms and 1s.
+	SlowThreshold time.Duration   // 0 (default value) is interpreted as the largest of Thresholds.
+}
+
+// WithinSLOName returns the name of the counter of observations within the SLO thresholds.
+func (t *SLOTimer) WithinSLOName() string {
+	return t.Name + ".within_slo"
+}
+
+// Duration takes a time.Duration -- the time to complete the indicated
+// operation -- and submits it to statsd, along with the SLO counters.
+//
+// The last parameter is an arbitrary array of tags as maps.
+func (t *SLOTimer) Duration(ctx context.Context, n time.Duration, ts ...Tags) {
+	tags := metricTags(ctx, t.Name, ts...)
+	rate := t.sampleRate(n, tags)
+	warnIfError(ctx, GetBackend().Distribution(ctx, t.Name, n.Seconds()*1000, tags, rate))
+	recordExemplar(ctx, t.Name, n, tags)
+
+	name := t.WithinSLOName()
+	for _, threshold := range t.Thresholds {
+		sloTags := append(ts[:len(ts):len(ts)], Tags{"slo": threshold, "within": n <= threshold})
+		warnIfError(ctx, GetBackend().Count(ctx, name, 1, metricTags(ctx, name, sloTags...), rate))
+	}
+}
+
+// Time runs a function, timing its execution, and submits the resulting
+// duration to statsd.
+//
+// The last parameter is an arbitrary array of tags as maps.
+func (t *SLOTimer) Time(ctx context.Context, fn func() error, ts ...Tags) error {
+	t1 := time.Now()
+	err := fn()
+	n := time.Since(t1)
+
+	ts = append(ts, Tags{"success": err == nil})
+	t.Duration(ctx, n, ts...)
+	return err
+}
+
+// StartTimer provides a way to collect a duration metric for a function call
+// in one line, see Timer.StartTimer.
+func (t *SLOTimer) StartTimer(ctx context.Context, ts ...Tags) Finisher {
+	return &timerFinisher{
+		timer:     t,
+		startTime: time.Now(),
+		tags:      ts,
+		ctx:       ctx,
+	}
+}
+
+// sampleRate returns 1 for failed or slow observations, and Rate otherwise.
+func (t *SLOTimer) sampleRate(n time.Duration, tags []string) float64 {
+	for _, tag := range tags {
+		if tag == "success:false" {
+			return 1
+		}
+	}
+
+	slow := t.SlowThreshold
+	if slow == 0 {
+		for _, threshold := range t.Thresholds {
+			if threshold > slow {
+				slow = threshold
+			}
+		}
+	}
+	if slow > 0 && n >= slow {
+		return 1
+	}
+	return t.Rate.Rate()
+}
@@ -0,0 +1,82 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOTimer_Duration(t *testing.T) {
+	recorder := WithTestBackend(t)
+	ctx := WithTag(context.Background(), "context", "ok")
+
+	timer := &SLOTimer{Name: "slo", Rate: 0.5, Thresholds: []time.Duration{100 * time.Millisecond, time.Second}}
+	timer.Duration(ctx, 50*time.Millisecond, Tags{"extra": "ok"})
+	timer.Duration(ctx, 500*time.Millisecond)
+
+	distributions := recorder.Find(DistributionType, "slo", "context:ok")
+	require.Len(t, distributions, 2)
+	assert.Equal(t, 50.0, distributions[0].Value)
+	assert.Equal(t, []string{"context:ok", "extra:ok"}, distributions[0].Tags)
+	assert.Equal(t, 0.5, distributions[0].Rate)
+
+	assert.Equal(t, int64(1), recorder.CountTotal("slo.within_slo", "slo:100ms", "within:true", "extra:ok", "context:ok"))
+	assert.Equal(t, int64(1), recorder.CountTotal("slo.within_slo", "slo:100ms", "within:false"))
+	assert.Equal(t, int64(2), recorder.CountTotal("slo.within_slo", "slo:1s", "within:true"))
+	assert.Equal(t, int64(0), recorder.CountTotal("slo.within_slo", "slo:1s", "within:false"))
+	for _, m := range recorder.Find(CountType, "slo.within_slo") {
+		assert.Equal(t, 0.5, m.Rate)
+	}
+}
+
+func TestSLOTimer_dynamicSampling(t *testing.T) {
+	recorder := WithTestBackend(t)
+	ctx := context.Background()
+
+	timer := &SLOTimer{Name: "slo", Rate: 0.1, Thresholds: []time.Duration{100 * time.Millisecond, time.Second}}
+	rateOf := func(n time.Duration, ts ...Tags) float64 {
+		recorder.Reset()
+		timer.Duration(ctx, n, ts...)
+		return recorder.Find(DistributionType, "slo")[0].Rate
+	}
+
+	assert.Equal(t, 0.1, rateOf(10*time.Millisecond))
+	assert.Equal(t, 0.1, rateOf(500*time.Millisecond), "slow observations default to the largest threshold")
+	assert.Equal(t, 1.0, rateOf(time.Second))
+	assert.Equal(t, 1.0, rateOf(time.Millisecond, Tags{"success": false}))
+	assert.Equal(t, 0.1, rateOf(time.Millisecond, Tags{"success": true}))
+
+	timer.SlowThreshold = 200 * time.Millisecond
+	assert.Equal(t, 1.0, rateOf(500*time.Millisecond))
+
+	recorder.Reset()
+	err := timer.Time(ctx, func() error { return errors.New("failed") })
+	assert.Error(t, err)
+	assert.Equal(t, 1.0, recorder.Find(DistributionType, "slo", "success:false")[0].Rate)
+
+	recorder.Reset()
+	func() (err error) {
+		defer timer.StartTimer(ctx).SuccessFinish(&err)
+		return errors.New("failed")
+	}()
+	assert.Equal(t, 1.0, recorder.Find(DistributionType, "slo", "success:false")[0].Rate)
+}
+
+func TestRegistry_SLOTimer(t *testing.T) {
+	r := NewRegistry()
+	timer := r.SLOTimer(Definition{Name: "request", TagKeys: []string{"route"}}, time.Second)
+	assert.Equal(t, []time.Duration{time.Second}, timer.Thresholds)
+
+	def, ok := r.Lookup("request")
+	require.True(t, ok)
+	assert.Equal(t, DistributionType, def.Type)
+
+	def, ok = r.Lookup("request.within_slo")
+	require.True(t, ok)
+	assert.Equal(t, CountType, def.Type)
+	assert.Equal(t, []string{"route", "slo", "within"}, def.TagKeys)
+}
@@ -0,0 +1,32 @@
+package statsd
+
+import (
+	"context"
+	"strings"
+)
+
+// Snapshot copies the tags attached to the Context, such that they can be restored with Restore on a Context
+// which isn't derived from it, for example by a worker pool or after being serialized into a job queue.
+//
+// Keys and values are formatted as they would be when recording a metric, such that the snapshot can be serialized
+// as is.
+// The tags of a WatchingTaggable are copied with their current values.
+func Snapshot(ctx context.Context) map[string]string {
+	set := getTagSet(ctx)
+	snapshot := make(map[string]string, len(set.entries))
+	for _, e := range set.entries {
+		// Colons are replaced in keys by formatTag, the first one separates the value.
+		_, value, _ := strings.Cut(e.formatted, ":")
+		snapshot[e.key] = value
+	}
+	return snapshot
+}
+
+// Restore attaches the tags of a Snapshot to the Context.
+func Restore(ctx context.Context, snapshot map[string]string) context.Context {
+	tags := make(Tags, len(snapshot))
+	for k, v := range snapshot {
+		tags[k] = v
+	}
+	return &keyValueContext{Context: ctx, tags: tags}
+}
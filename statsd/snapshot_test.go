@@ -0,0 +1,34 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := WithTags(context.Background(), Tags{"route": "/hello", "timeout": time.Second, "url": "http://host:80"})
+	ctx = WithTag(ctx, "bad|key", "a,b")
+
+	snapshot := Snapshot(ctx)
+	assert.Equal(t, map[string]string{
+		"route":   "/hello",
+		"timeout": "1s",
+		"url":     "http://host:80",
+		"bad_key": "a_b",
+	}, snapshot)
+
+	b, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	restored := Restore(context.Background(), decoded)
+	assert.Equal(t, getStatsTags(ctx), getStatsTags(restored))
+
+	assert.Empty(t, Snapshot(context.Background()))
+}
@@ -5,8 +5,10 @@ package statsd
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,10 +19,11 @@ import (
 var log = logger.New("statsd")
 
 // Backend is an interface to a Statsd instance, currently implemented by
-// nullBackend and NewDatadogBackend (go-dogstatsd).
+// nullBackend, NewDatadogBackend (go-dogstatsd) and NewUDPBackend/NewUDSBackend.
 //
 // The statsd protocol supports more types than we do: we can add these as we
-// need them.
+// need them. Events and service checks are supported by the backends
+// implementing the optional EventSender and ServiceCheckSender interfaces.
 type Backend interface {
 	// Gauge measures the value of a metric at a particular time.
 	Gauge(ctx context.Context, name string, value float64, tags []string, rate float64) error
@@ -36,11 +39,81 @@ type Backend interface {
 	Timing(ctx context.Context, name string, value time.Duration, tags []string, rate float64) error
 }
 
-var currentBackend = NewNullBackend()
+// Flusher is implemented by the backends buffering metrics before submitting them.
+type Flusher interface {
+	// Flush submits the buffered metrics.
+	Flush() error
+}
+
+// Closer is implemented by the backends holding resources, such as connections or goroutines.
+type Closer interface {
+	// Close flushes the buffered metrics, if any, and releases the resources.
+	// The Backend must not be used after being closed.
+	Close() error
+}
+
+var (
+	defaultBackend = NewNullBackend()
+	currentBackend atomic.Pointer[backendHolder]
+)
+
+// backendHolder allows storing Backends of different concrete types in currentBackend.
+type backendHolder struct {
+	Backend
+}
+
+// GetBackend returns the current backend.
+func GetBackend() Backend {
+	if h := currentBackend.Load(); h != nil {
+		return h.Backend
+	}
+	return defaultBackend
+}
 
 // SetBackend replaces the current backend with the given Backend.
+// It is safe to call while metrics are being recorded, but the previous backend is neither flushed nor closed,
+// see SwapBackend.
 func SetBackend(b Backend) {
-	currentBackend = b
+	currentBackend.Store(&backendHolder{b})
+}
+
+// SwapBackend replaces the current backend with the given Backend, and returns the previous one.
+// Combined with FlushAndClose, it allows gracefully replacing a backend:
+//
+//	err := statsd.FlushAndClose(statsd.SwapBackend(newBackend))
+func SwapBackend(b Backend) Backend {
+	if prev := currentBackend.Swap(&backendHolder{b}); prev != nil {
+		return prev.Backend
+	}
+	return defaultBackend
+}
+
+// Flush flushes the current backend, if it implements Flusher.
+func Flush() error {
+	return flush(GetBackend())
+}
+
+func flush(b Backend) error {
+	if f, ok := b.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close replaces the current backend with a null backend, then flushes and closes the previous one.
+// Metrics recorded afterwards are discarded. It is meant to be called when the application shuts down.
+func Close() error {
+	return FlushAndClose(SwapBackend(NewNullBackend()))
+}
+
+// FlushAndClose flushes the Backend if it implements Flusher, then closes it if it implements Closer.
+func FlushAndClose(b Backend) error {
+	var closeErr error
+	flushErr := flush(b)
+	if c, ok := b.(Closer); ok {
+		closeErr = c.Close()
+	}
+	return stderrors.Join(flushErr, closeErr)
 }
 
 // ErrUnknownBackend is returned when the statsd backend implementation is not known.
@@ -54,6 +127,10 @@ func NewBackend(impl, addr, prefix string, tags ...string) (Backend, error) {
 	switch strings.ToLower(impl) {
 	case "datadog":
 		b, err = NewDatadogBackend(addr, prefix, tags)
+	case "udp":
+		b, err = NewUDPBackend(addr, prefix, tags)
+	case "uds":
+		b, err = NewUDSBackend(addr, prefix, tags)
 	case "log":
 		b = NewLogBackend(prefix, tags)
 	case "null":
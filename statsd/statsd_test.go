@@ -1,7 +1,9 @@
 package statsd
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -11,6 +13,8 @@ import (
 func TestBackend(t *testing.T) {
 	dd, err := NewDatadogBackend("localhost:8125", "catwalk", []string{"global:tag"})
 	require.NoError(t, err)
+	udp, err := NewUDPBackend("localhost:8125", "catwalk", []string{"global:tag"})
+	require.NoError(t, err)
 
 	tests := []struct {
 		impl string
@@ -18,18 +22,19 @@ func TestBackend(t *testing.T) {
 		err  error
 	}{
 		{impl: "datadog", exp: dd},
+		{impl: "udp", exp: udp},
 		{impl: "null", exp: NewNullBackend()},
 		{impl: "log", exp: NewLogBackend("catwalk", []string{})},
 		{err: ErrUnknownBackend},
 		{impl: "WHAT", err: ErrUnknownBackend},
 	}
 
+	current := GetBackend()
 	for _, test := range tests {
-		currentBackend = nil
 		b, err := NewBackend(test.impl, "localhost:8125", "catwalk", "global:tag")
 		if test.err != nil {
 			fmt.Printf("testing for: %s\n", test.impl)
-			require.Nil(t, currentBackend)
+			require.True(t, current == GetBackend(), "NewBackend must not replace the current backend")
 			require.Equal(t, test.err, errors.Cause(err))
 			continue
 		}
@@ -38,3 +43,75 @@ func TestBackend(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+type closingBackend struct {
+	*RecordingBackend
+	flushed int
+	closed  int
+}
+
+func (b *closingBackend) Flush() error {
+	b.flushed++
+	return nil
+}
+
+func (b *closingBackend) Close() error {
+	b.closed++
+	return errors.New("close error")
+}
+
+func TestSwapBackend(t *testing.T) {
+	defer SetBackend(NewNullBackend())
+
+	b1 := NewRecordingBackend()
+	b2 := NewRecordingBackend()
+	SetBackend(b1)
+
+	prev := SwapBackend(b2)
+	require.True(t, prev == b1)
+	require.True(t, GetBackend() == b2)
+
+	(&Counter{Name: "counter"}).Incr(context.Background())
+	require.Empty(t, b1.Metrics())
+	require.Equal(t, int64(1), b2.CountTotal("counter"))
+}
+
+func TestSetBackend_concurrent(t *testing.T) {
+	defer SetBackend(NewNullBackend())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetBackend(NewRecordingBackend())
+		}()
+		go func() {
+			defer wg.Done()
+			(&Counter{Name: "counter"}).Incr(context.Background())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFlushAndClose(t *testing.T) {
+	defer SetBackend(NewNullBackend())
+
+	b := &closingBackend{RecordingBackend: NewRecordingBackend()}
+	SetBackend(b)
+
+	require.NoError(t, Flush())
+	require.Equal(t, 1, b.flushed)
+	require.Equal(t, 0, b.closed)
+
+	require.EqualError(t, Close(), "close error")
+	require.Equal(t, 2, b.flushed)
+	require.Equal(t, 1, b.closed)
+
+	// Metrics are discarded after closing
+	(&Counter{Name: "counter"}).Incr(context.Background())
+	require.Empty(t, b.Metrics())
+
+	// Backends without Flush or Close are ignored
+	require.NoError(t, FlushAndClose(NewRecordingBackend()))
+}
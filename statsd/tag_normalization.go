@@ -0,0 +1,140 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTagLength is the maximum length of a "key:value" tag accepted by Datadog. Longer tags are truncated.
+const MaxTagLength = 200
+
+var lowercaseTagKeys atomic.Bool
+
+// SetLowercaseTagKeys sets whether tag keys are lowercased, as Datadog does upon ingestion. Disabled by default.
+// It should be called once at application startup, along with SetBackend.
+func SetLowercaseTagKeys(enabled bool) {
+	lowercaseTagKeys.Store(enabled)
+}
+
+// Characters which would corrupt a DogStatsD line, and are replaced with an underscore.
+// Colons are allowed in values, since only the first colon separates the key from the value.
+var (
+	tagKeyReplacer   = strings.NewReplacer(",", "_", "|", "_", "#", "_", ":", "_", "\n", "_", "\r", "_")
+	tagValueReplacer = strings.NewReplacer(",", "_", "|", "_", "\n", "_", "\r", "_")
+)
+
+var warnedTagKeys = struct {
+	sync.Mutex
+	keys map[string]struct{}
+}{
+	keys: map[string]struct{}{},
+}
+
+// formatTag formats a key-value pair as a "key:value" tag:
+//
+//   - Characters corrupting the DogStatsD line are replaced with an underscore.
+//   - Keys are lowercased if enabled with SetLowercaseTagKeys.
+//   - Tags are truncated to MaxTagLength.
+//   - Values are formatted with formatTagValue.
+//
+// A warning is logged the first time a key or a value of a given key is sanitized or truncated.
+func formatTag(ctx context.Context, key string, value interface{}) string {
+	rawValue := formatTagValue(value)
+
+	k := tagKeyReplacer.Replace(key)
+	v := tagValueReplacer.Replace(rawValue)
+	rewritten := k != key || v != rawValue
+
+	if lowercaseTagKeys.Load() {
+		k = strings.ToLower(k)
+	}
+
+	tag := k + ":" + v
+	if len(tag) > MaxTagLength {
+		tag = truncateUTF8(tag, MaxTagLength)
+		rewritten = true
+	}
+
+	if rewritten {
+		warnTagRewritten(ctx, key, tag)
+	}
+	return tag
+}
+
+// formatTagValue formats time.Duration, bool, error and fmt.Stringer values consistently.
+// Pointers are dereferenced, and nil values are formatted as "<nil>", like fmt does.
+func formatTagValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return "<nil>"
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		return formatTagValue(rv.Elem().Interface())
+	}
+	return fmt.Sprint(value)
+}
+
+// tagKey returns the key of a tag formatted by formatTag.
+func tagKey(tag string) string {
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// hasTagKey returns whether the n tags sorted by tag(i) include a tag with the key.
+// Since the key ends at the first colon, the tags with a given key are contiguous.
+func hasTagKey(n int, tag func(i int) string, key string) bool {
+	for i := sort.Search(n, func(i int) bool { return tag(i) >= key }); i < n && strings.HasPrefix(tag(i), key); i++ {
+		if t := tag(i); len(t) == len(key) || t[len(key)] == ':' {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func warnTagRewritten(ctx context.Context, key string, tag string) {
+	warnedTagKeys.Lock()
+	_, warned := warnedTagKeys.keys[key]
+	warnedTagKeys.keys[key] = struct{}{}
+	warnedTagKeys.Unlock()
+
+	if warned {
+		return
+	}
+	log(ctx, nil).
+		WithField("tagKey", key).
+		WithField("tag", tag).
+		Warn("statsd tag contains invalid characters or is too long, it was rewritten")
+}
@@ -0,0 +1,124 @@
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer"
+}
+
+func TestFormatTagValue(t *testing.T) {
+	str := "pointed"
+	var nilStringer *net.IPNet
+
+	for _, tt := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{"string", "string"},
+		{42, "42"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{1500 * time.Millisecond, "1.5s"},
+		{errors.New("failed"), "failed"},
+		{testStringer{}, "stringer"},
+		{&str, "pointed"},
+		{nil, "<nil>"},
+		{nilStringer, "<nil>"},
+	} {
+		assert.Equal(t, tt.expected, formatTagValue(tt.value), "%#v", tt.value)
+	}
+}
+
+// resetWarnedTagKeys forgets the keys warned about by formatTag, such that tests can count the warnings.
+func resetWarnedTagKeys(t *testing.T) {
+	reset := func() {
+		warnedTagKeys.Lock()
+		warnedTagKeys.keys = map[string]struct{}{}
+		warnedTagKeys.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestGetStatsTags_normalization(t *testing.T) {
+	resetWarnedTagKeys(t)
+
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	logging := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(logging)
+
+	ctx := context.Background()
+	long := strings.Repeat("é", MaxTagLength)
+
+	tags := getStatsTags(ctx, Tags{
+		"route":         "/a,b|c\nd",
+		"time":          "12:30",
+		"key:with|pipe": "ok",
+		"long":          long,
+		"Camel":         "case",
+	})
+	require.Len(t, tags, 5)
+	assert.Equal(t, "Camel:case", tags[0])
+	assert.Equal(t, "key_with_pipe:ok", tags[1])
+	assert.Equal(t, "route:/a_b_c_d", tags[3])
+	assert.Equal(t, "time:12:30", tags[4])
+
+	assert.LessOrEqual(t, len(tags[2]), MaxTagLength)
+	assert.True(t, strings.HasPrefix(tags[2], "long:éé"))
+	assert.True(t, strings.HasSuffix(tags[2], "é"), "truncated on a rune boundary")
+
+	// Warned once per key
+	getStatsTags(ctx, Tags{"route": "/x,y"})
+	assert.Equal(t, 1, strings.Count(logging.String(), "tagKey=route"))
+	assert.Equal(t, 1, strings.Count(logging.String(), "tagKey=long"))
+	assert.Equal(t, 1, strings.Count(logging.String(), "tagKey=\"key:with|pipe\""))
+	assert.NotContains(t, logging.String(), "tagKey=time")
+	assert.NotContains(t, logging.String(), "tagKey=Camel")
+}
+
+func TestSetLowercaseTagKeys(t *testing.T) {
+	defer SetLowercaseTagKeys(false)
+	SetLowercaseTagKeys(true)
+
+	logOutput := logrus.StandardLogger().Out
+	defer logrus.StandardLogger().SetOutput(logOutput)
+	logging := &bytes.Buffer{}
+	logrus.StandardLogger().SetOutput(logging)
+
+	recorder := WithTestBackend(t)
+	metric := DefaultRegistry.Counter(Definition{Name: "lowercase.test", TagKeys: []string{"statusCode"}})
+	metric.Incr(context.Background(), Tags{"statusCode": 200})
+
+	assert.Equal(t, int64(1), recorder.CountTotal("lowercase.test", "statuscode:200"))
+	assert.Empty(t, logging.String(), "lowercased keys match the registered keys")
+}
+
+func TestGetStatsTags_lowercaseDuplicates(t *testing.T) {
+	defer SetLowercaseTagKeys(false)
+	SetLowercaseTagKeys(true)
+
+	ctx := WithTags(context.Background(), Tags{"Key": "a", "key": "a", "Other": "b"})
+	assert.Equal(t, []string{"key:a", "other:b"}, getStatsTags(ctx))
+
+	// Extra tags override the context tags with the same normalized key
+	assert.Equal(t, []string{"key:c", "other:b"}, getStatsTags(ctx, Tags{"KEY": "c"}))
+	assert.Equal(t, []string{"key:c", "other:b"}, getStatsTags(ctx, Tags{"KEY": "c", "kEy": "c"}))
+
+	ctx = WithTag(ctx, "OTHER", "d")
+	assert.Equal(t, []string{"key:a", "other:d"}, getStatsTags(ctx))
+}
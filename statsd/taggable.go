@@ -2,8 +2,9 @@ package statsd
 
 import (
 	"context"
-	"fmt"
 	"sort"
+	"sync"
+	"time"
 )
 
 // This create a private key-space in the Context, meaning that only this package can get or set "contextKey" types
@@ -20,23 +21,127 @@ type Taggable interface {
 	StatsTags() Tags
 }
 
+// tagSet is the merged tags of a Context, formatted with formatTag.
+// It is immutable, such that it can be cached and shared between goroutines.
+type tagSet struct {
+	entries []tagEntry // Sorted by formatted tag.
+	// formatted holds the formatted tags of entries, in the same order. It is returned as is by getStatsTags.
+	formatted []string
+	// dynamic is set when a WatchingTaggable, or a value which isn't cacheable, is part of the chain,
+	// in which case the tagSet can't be cached.
+	dynamic bool
+	// lowercase is the SetLowercaseTagKeys setting the tags were formatted with.
+	lowercase bool
+}
+
+type tagEntry struct {
+	key       string // The key of formatted, such that keys equal once normalized override each other.
+	formatted string
+}
+
+var emptyTagSet = &tagSet{}
+
+// newTagSet creates a tagSet by merging tags over parent.
+// Only the tags which aren't part of the parent are formatted.
+func newTagSet(ctx context.Context, parent *tagSet, tags Tags, dynamic bool) *tagSet {
+	added := make([]tagEntry, 0, len(tags))
+	for k, v := range tags {
+		formatted := formatTag(ctx, k, v)
+		added = append(added, tagEntry{key: tagKey(formatted), formatted: formatted})
+	}
+	sort.Slice(added, func(i, j int) bool {
+		return added[i].formatted < added[j].formatted
+	})
+	added = dedupeTagEntries(added)
+
+	// Merge the sorted entries, skipping the parent entries overridden by tags.
+	s := &tagSet{
+		entries:   make([]tagEntry, 0, len(parent.entries)+len(added)),
+		dynamic:   dynamic || parent.dynamic || !cacheableTags(tags),
+		lowercase: lowercaseTagKeys.Load(),
+	}
+	addedTag := func(i int) string { return added[i].formatted }
+	i := 0
+	for _, e := range parent.entries {
+		if hasTagKey(len(added), addedTag, e.key) {
+			continue
+		}
+		for i < len(added) && added[i].formatted < e.formatted {
+			s.entries = append(s.entries, added[i])
+			i++
+		}
+		s.entries = append(s.entries, e)
+	}
+	s.entries = append(s.entries, added[i:]...)
+
+	s.formatted = make([]string, len(s.entries))
+	for i, e := range s.entries {
+		s.formatted[i] = e.formatted
+	}
+	return s
+}
+
+// dedupeTagEntries removes the sorted entries with the same key as the previous one, such that a single tag is kept
+// for keys which only differ before normalization, like "Key" and "key" when SetLowercaseTagKeys is enabled.
+func dedupeTagEntries(entries []tagEntry) []tagEntry {
+	deduped := entries[:0]
+	for _, e := range entries {
+		if len(deduped) > 0 && deduped[len(deduped)-1].key == e.key {
+			continue
+		}
+		deduped = append(deduped, e)
+	}
+	return deduped
+}
+
+// cacheableTags returns whether the formatted tags can be cached, which is when all the values are of basic types.
+// Other values, like pointers and fmt.Stringers, may format differently every time.
+func cacheableTags(tags Tags) bool {
+	for _, v := range tags {
+		switch v.(type) {
+		case nil, string, bool, time.Duration,
+			int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64, uintptr,
+			float32, float64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // keyValueContext wraps a parent Context and override the behaviour of Value(), similar to how context.valueCtx works.
 // The difference with context.valueCtx is that it _appends_ to the parent's value instead of replacing it.
+//
+// The merged tags are computed the first time they are requested, then cached, unless a WatchingTaggable or a value
+// which isn't cacheable is part of the parent chain. They are computed again if SetLowercaseTagKeys is changed.
 type keyValueContext struct {
 	context.Context
-	key   string
-	value interface{}
+	tags Tags
+
+	once sync.Once
+	set  *tagSet
 }
 
 func (c *keyValueContext) Value(key interface{}) interface{} {
 	if key == tagsKey {
-		prev := getStatsTagsMap(c.Context)
-		prev[c.key] = c.value
-		return prev
+		return c.tagSet()
 	}
 	return c.Context.Value(key)
 }
 
+func (c *keyValueContext) tagSet() *tagSet {
+	var set *tagSet
+	c.once.Do(func() {
+		set = newTagSet(c.Context, getTagSet(c.Context), c.tags, false)
+		c.set = set
+	})
+	if set == nil && (c.set.dynamic || c.set.lowercase != lowercaseTagKeys.Load()) {
+		return newTagSet(c.Context, getTagSet(c.Context), c.tags, false)
+	}
+	return c.set
+}
+
 // taggableContext is the same as tagContext, but with dynamic tags.
 type taggableContext struct {
 	context.Context
@@ -45,28 +150,30 @@ type taggableContext struct {
 
 func (c *taggableContext) Value(key interface{}) interface{} {
 	if key == tagsKey {
-		prev := getStatsTagsMap(c.Context)
-		for k, v := range c.taggable.StatsTags() {
-			prev[k] = v
-		}
-		return prev
+		return newTagSet(c.Context, getTagSet(c.Context), c.taggable.StatsTags(), true)
 	}
 	return c.Context.Value(key)
 }
 
 // WithTag attaches a key-value pair to a Context.
 // Upon recording a metric, the pair will be attached as a tag.
+//
+// Values of basic types, such as strings, numbers, bools and time.Duration, are formatted once and cached in the
+// Context. Other values, such as pointers and fmt.Stringers, are formatted every time a metric is recorded,
+// such that their current value is used.
 func WithTag(ctx context.Context, k string, v interface{}) context.Context {
-	return &keyValueContext{Context: ctx, key: k, value: v}
+	return &keyValueContext{Context: ctx, tags: Tags{k: v}}
 }
 
 // WithTags attaches fields to a Context.
 // Upon recording a metric, those fields will be attached as tags.
+// Values are formatted and cached like WithTag does.
 func WithTags(ctx context.Context, t Tags) context.Context {
+	tags := make(Tags, len(t))
 	for k, v := range t {
-		ctx = WithTag(ctx, k, v)
+		tags[k] = v
 	}
-	return ctx
+	return &keyValueContext{Context: ctx, tags: tags}
 }
 
 // WithTaggable attaches a Taggable's tags to a Context.
@@ -81,35 +188,87 @@ func WatchingTaggable(ctx context.Context, t Taggable) context.Context {
 	return &taggableContext{Context: ctx, taggable: t}
 }
 
-// getStatsTags returns the merged tags as a list
-// Meant to be used by the metrics when inlining the tags
+// metricTags returns the tags to be submitted with the metric name.
+// These are the tags from getStatsTags, checked against DefaultRegistry, with the cardinality limiter applied.
+func metricTags(ctx context.Context, name string, extraTagList ...Tags) []string {
+	tags := getStatsTags(ctx, extraTagList...)
+	DefaultRegistry.checkTags(ctx, name, tags)
+	if cardinalityLimiter != nil {
+		tags = cardinalityLimiter.Limit(ctx, name, tags)
+	}
+	return tags
+}
+
+// getStatsTags returns the merged tags as a list, normalized with formatTag and sorted.
+// Meant to be used by the metrics when inlining the tags.
+//
+// Without extra tags, the formatted tags cached in the Context are returned without copying: the list must not be
+// modified.
 func getStatsTags(ctx context.Context, extraTagList ...Tags) []string {
-	tags := getStatsTagsMap(ctx)
-	for _, extraTags := range extraTagList {
-		for k, v := range extraTags {
-			tags[k] = v
+	set := getTagSet(ctx)
+
+	var extraTags Tags
+	switch len(extraTagList) {
+	case 0:
+	case 1:
+		extraTags = extraTagList[0]
+	default:
+		extraTags = Tags{}
+		for _, tags := range extraTagList {
+			for k, v := range tags {
+				extraTags[k] = v
+			}
 		}
 	}
 
-	list := make([]string, 0, len(tags))
-	for k, v := range tags {
-		list = append(list, fmt.Sprintf("%s:%v", k, v))
+	if len(extraTags) == 0 {
+		return set.formatted[:len(set.formatted):len(set.formatted)]
+	}
+
+	extra := make([]string, 0, len(extraTags))
+	for k, v := range extraTags {
+		extra = append(extra, formatTag(ctx, k, v))
 	}
+	sort.Strings(extra)
+	extra = dedupeTags(extra)
 
-	sort.Strings(list)
-	return list
+	// Merge the sorted lists, skipping the context tags overridden by the extra tags.
+	list := make([]string, 0, len(set.entries)+len(extra))
+	extraTag := func(i int) string { return extra[i] }
+	i := 0
+	for _, e := range set.entries {
+		if hasTagKey(len(extra), extraTag, e.key) {
+			continue
+		}
+		for i < len(extra) && extra[i] < e.formatted {
+			list = append(list, extra[i])
+			i++
+		}
+		list = append(list, e.formatted)
+	}
+	return append(list, extra[i:]...)
 }
 
-// getStatsTagsMap returns the merged tags as a map
-func getStatsTagsMap(ctx context.Context) Tags {
-	if ctx != nil {
-		fields, _ := ctx.Value(tagsKey).(Tags)
-		if fields != nil {
-			return fields
+// dedupeTags is the same as dedupeTagEntries, for sorted formatted tags.
+func dedupeTags(tags []string) []string {
+	deduped := tags[:0]
+	for _, tag := range tags {
+		if len(deduped) > 0 && tagKey(deduped[len(deduped)-1]) == tagKey(tag) {
+			continue
 		}
+		deduped = append(deduped, tag)
 	}
+	return deduped
+}
 
-	return Tags{}
+// getTagSet returns the merged tags of a Context.
+func getTagSet(ctx context.Context) *tagSet {
+	if ctx != nil {
+		if s, ok := ctx.Value(tagsKey).(*tagSet); ok {
+			return s
+		}
+	}
+	return emptyTagSet
 }
 
 // SelectKeys returns a map containing only the specified fields
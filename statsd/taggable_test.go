@@ -3,6 +3,7 @@ package statsd
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -171,3 +172,135 @@ func TestWatchingTaggable(t *testing.T) {
 		"b:1",
 	}, getStatsTags(ctx))
 }
+
+func TestWithTags_copiesTags(t *testing.T) {
+	tags := Tags{"a": "1"}
+	ctx := WithTags(context.Background(), tags)
+	tags["a"] = "2"
+
+	assert.Equal(t, []string{"a:1"}, getStatsTags(ctx))
+}
+
+func TestWatchingTaggable_childContext(t *testing.T) {
+	provider := &testTaggable{"a": 1}
+	ctx := WatchingTaggable(context.Background(), provider)
+	ctx = WithTag(ctx, "b", 1)
+
+	assert.Equal(t, []string{"a:1", "b:1"}, getStatsTags(ctx))
+
+	// Contexts derived from a WatchingTaggable aren't cached
+	(*provider)["a"] = 2
+	assert.Equal(t, []string{"a:2", "b:1"}, getStatsTags(ctx))
+}
+
+type stateStringer struct {
+	state *string
+}
+
+func (s stateStringer) String() string {
+	return *s.state
+}
+
+func TestWithTag_notCacheableValues(t *testing.T) {
+	n := 1
+	state := "starting"
+	ctx := WithTag(context.Background(), "n", &n)
+	ctx = WithTag(ctx, "state", stateStringer{&state})
+	ctx = WithTag(ctx, "static", "yes")
+
+	assert.Equal(t, []string{"n:1", "state:starting", "static:yes"}, getStatsTags(ctx))
+
+	// Pointers and Stringers are formatted every time, with their current value
+	n = 2
+	state = "running"
+	assert.Equal(t, []string{"n:2", "state:running", "static:yes"}, getStatsTags(ctx))
+}
+
+func TestWithTag_lowercaseTagKeysChanged(t *testing.T) {
+	defer SetLowercaseTagKeys(false)
+
+	parent := WithTag(context.Background(), "statusCode", 200)
+	ctx := WithTag(parent, "Route", "/")
+	assert.Equal(t, []string{"Route:/", "statusCode:200"}, getStatsTags(ctx))
+
+	// Cached tags are formatted again when the setting changes
+	SetLowercaseTagKeys(true)
+	assert.Equal(t, []string{"route:/", "statuscode:200"}, getStatsTags(ctx))
+	assert.Equal(t, []string{"statuscode:200"}, getStatsTags(parent))
+}
+
+func TestGetStatsTags_extraTags(t *testing.T) {
+	ctx := WithTags(context.Background(), Tags{"a": 1, "c": 1, "e": 1})
+
+	assert.Equal(t, []string{"a:1", "b:2", "c:2", "d:3", "e:1"}, getStatsTags(ctx, Tags{"b": 2, "c": 2}, Tags{"d": 3}))
+	assert.Equal(t, []string{"a:1", "c:1", "e:1"}, getStatsTags(ctx), "extra tags don't modify the context")
+}
+
+func TestGetStatsTags_concurrent(t *testing.T) {
+	ctx := WithTags(context.Background(), Tags{"a": 1, "b": 2})
+	ctx = WithTag(ctx, "c", 3)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, []string{"a:1", "b:2", "c:3"}, getStatsTags(ctx))
+		}()
+	}
+	wg.Wait()
+}
+
+func benchmarkContext() context.Context {
+	ctx := context.Background()
+	for i := 0; i < 12; i++ {
+		ctx = WithTag(ctx, fmt.Sprintf("key%02d", i), fmt.Sprintf("value%02d", i))
+	}
+	return ctx
+}
+
+func BenchmarkGetStatsTags(b *testing.B) {
+	ctx := benchmarkContext()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		getStatsTags(ctx)
+	}
+}
+
+func BenchmarkGetStatsTags_extraTags(b *testing.B) {
+	ctx := benchmarkContext()
+	extra := Tags{"success": true, "key00": "override"}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		getStatsTags(ctx, extra)
+	}
+}
+
+func BenchmarkGetStatsTags_watchingTaggable(b *testing.B) {
+	ctx := WatchingTaggable(benchmarkContext(), &testTaggable{"dynamic": 1})
+	ctx = WithTag(ctx, "child", "value")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		getStatsTags(ctx)
+	}
+}
+
+func BenchmarkCounter_Incr(b *testing.B) {
+	defer SetBackend(NewNullBackend())
+	SetBackend(NewNullBackend())
+
+	ctx := benchmarkContext()
+	counter := &Counter{Name: "benchmark"}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		counter.Incr(ctx)
+	}
+}
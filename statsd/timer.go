@@ -15,8 +15,9 @@ type Timer collector
 //
 // The last parameter is an arbitrary array of tags as maps.
 func (t *Timer) Duration(ctx context.Context, n time.Duration, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Distribution(ctx, t.Name, n.Seconds()*1000, tags, t.Rate.Rate()))
+	tags := metricTags(ctx, t.Name, ts...)
+	warnIfError(ctx, GetBackend().Distribution(ctx, t.Name, n.Seconds()*1000, tags, t.Rate.Rate()))
+	recordExemplar(ctx, t.Name, n, tags)
 }
 
 // Time runs a function, timing its execution, and submits the resulting
@@ -39,8 +40,13 @@ type Finisher interface {
 	SetTags(ts ...Tags)
 }
 
+// durationRecorder is implemented by Timer and SLOTimer.
+type durationRecorder interface {
+	Duration(ctx context.Context, n time.Duration, ts ...Tags)
+}
+
 type timerFinisher struct {
-	timer     *Timer
+	timer     durationRecorder
 	startTime time.Time
 	tags      []Tags
 	ctx       context.Context
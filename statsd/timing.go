@@ -12,6 +12,6 @@ type Timing collector
 
 // Duration takes a time.Duration  -- the time the operation took -- and submits it to StatsD.
 func (t *Timing) Duration(ctx context.Context, n time.Duration, ts ...Tags) {
-	tags := getStatsTags(ctx, ts...)
-	warnIfError(ctx, currentBackend.Timing(ctx, t.Name, n, tags, t.Rate.Rate()))
+	tags := metricTags(ctx, t.Name, ts...)
+	warnIfError(ctx, GetBackend().Timing(ctx, t.Name, n, tags, t.Rate.Rate()))
 }
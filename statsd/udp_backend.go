@@ -0,0 +1,239 @@
+package statsd
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Shopify/goose/random"
+)
+
+const (
+	// DefaultUDPMaxPacketSize is the largest payload sent in a single UDP datagram.
+	// It fits in an Ethernet MTU (1500) once the IP and UDP headers are accounted for.
+	DefaultUDPMaxPacketSize = 1432
+
+	// DefaultUDSMaxPacketSize is the largest payload sent in a single Unix datagram.
+	DefaultUDSMaxPacketSize = 8192
+
+	// DefaultBufferFlushInterval is how often buffered lines are sent, even if a packet is not full.
+	DefaultBufferFlushInterval = 100 * time.Millisecond
+
+	// flushWarningInterval is the minimum interval between the warnings of the flush loop, such that an
+	// unreachable agent doesn't produce a warning every flush.
+	flushWarningInterval = 10 * time.Second
+)
+
+// ErrLineTooLarge is returned when a metric doesn't fit in a packet, in which case it is dropped.
+var ErrLineTooLarge = errors.New("statsd line larger than the maximum packet size")
+
+// NewUDPBackend creates a Backend that sends metrics over UDP to endpoint (e.g. "localhost:8125"), using the
+// DogStatsD line format. Lines are batched in packets of at most DefaultUDPMaxPacketSize bytes and flushed every
+// DefaultBufferFlushInterval.
+//
+// Unlike NewDatadogBackend, this backend has no external dependency and is compatible with vanilla statsd and
+// Telegraf, which ignore the DogStatsD extensions they don't support.
+//
+// `namespace` is an optional prefix to be prepended to every metric submitted.
+// `tags` is a set of tags that will be included with every metric submitted.
+// STATSD_DEFAULT_TAGS env variable will be read automatically and added to default tags.
+func NewUDPBackend(endpoint, namespace string, tags []string) (Backend, error) {
+	conn, err := net.Dial("udp", endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to dial statsd over udp")
+	}
+	return newPacketBackend(conn, namespace, tags, DefaultUDPMaxPacketSize, DefaultBufferFlushInterval), nil
+}
+
+// NewUDSBackend is the same as NewUDPBackend, but sends metrics over a Unix datagram socket located at path.
+// Packets are at most DefaultUDSMaxPacketSize bytes.
+func NewUDSBackend(path, namespace string, tags []string) (Backend, error) {
+	conn, err := net.Dial("unixgram", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to dial statsd over unix socket")
+	}
+	return newPacketBackend(conn, namespace, tags, DefaultUDSMaxPacketSize, DefaultBufferFlushInterval), nil
+}
+
+func newPacketBackend(conn net.Conn, namespace string, tags []string, maxPacketSize int, flushInterval time.Duration) *packetBackend {
+	b := &packetBackend{
+		conn:          conn,
+		namespace:     namespace,
+		tags:          append(defaultTagsFromEnv(), tags...),
+		maxPacketSize: maxPacketSize,
+		random:        random.NewLocked(),
+		buf:           make([]byte, 0, maxPacketSize),
+		stop:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	go b.flushLoop(flushInterval)
+	return b
+}
+
+type packetBackend struct {
+	conn          net.Conn
+	namespace     string
+	tags          []string
+	maxPacketSize int
+	random        *rand.Rand
+
+	l   sync.Mutex
+	buf []byte
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+func (b *packetBackend) Gauge(_ context.Context, name string, value float64, tags []string, rate float64) error {
+	return b.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags, rate)
+}
+
+func (b *packetBackend) Count(_ context.Context, name string, value int64, tags []string, rate float64) error {
+	return b.send(name, strconv.FormatInt(value, 10), "c", tags, rate)
+}
+
+func (b *packetBackend) Histogram(_ context.Context, name string, value float64, tags []string, rate float64) error {
+	return b.send(name, strconv.FormatFloat(value, 'f', -1, 64), "h", tags, rate)
+}
+
+func (b *packetBackend) Distribution(_ context.Context, name string, value float64, tags []string, rate float64) error {
+	return b.send(name, strconv.FormatFloat(value, 'f', -1, 64), "d", tags, rate)
+}
+
+func (b *packetBackend) Set(_ context.Context, name string, value string, tags []string, rate float64) error {
+	return b.send(name, value, "s", tags, rate)
+}
+
+func (b *packetBackend) Timing(_ context.Context, name string, value time.Duration, tags []string, rate float64) error {
+	ms := float64(value) / float64(time.Millisecond)
+	return b.send(name, strconv.FormatFloat(ms, 'f', -1, 64), "ms", tags, rate)
+}
+
+// Flush sends the buffered lines immediately.
+func (b *packetBackend) Flush() error {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return b.flushLocked()
+}
+
+// Close flushes the buffered lines, stops the flush loop and closes the connection.
+func (b *packetBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		<-b.stopped
+
+		err = b.Flush()
+		if closeErr := b.conn.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+func (b *packetBackend) send(name, value, mType string, tags []string, rate float64) error {
+	if rate < 1 && b.random.Float64() > rate {
+		return nil
+	}
+
+	line := b.appendLine(nil, name, value, mType, tags, rate)
+	if len(line) > b.maxPacketSize {
+		return errors.Wrapf(ErrLineTooLarge, "dropped %s%s (%d bytes)", b.namespace, name, len(line))
+	}
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if len(b.buf) > 0 && len(b.buf)+1+len(line) > b.maxPacketSize {
+		if err := b.flushLocked(); err != nil {
+			return err
+		}
+	}
+	if len(b.buf) > 0 {
+		b.buf = append(b.buf, '\n')
+	}
+	b.buf = append(b.buf, line...)
+	return nil
+}
+
+// appendLine encodes a metric in the DogStatsD format:
+// <namespace><name>:<value>|<type>[|@<rate>][|#<tag>,<tag>]
+func (b *packetBackend) appendLine(buf []byte, name, value, mType string, tags []string, rate float64) []byte {
+	buf = append(buf, b.namespace...)
+	buf = append(buf, name...)
+	buf = append(buf, ':')
+	buf = append(buf, value...)
+	buf = append(buf, '|')
+	buf = append(buf, mType...)
+
+	if rate < 1 {
+		buf = append(buf, "|@"...)
+		buf = strconv.AppendFloat(buf, rate, 'f', -1, 64)
+	}
+
+	if len(b.tags)+len(tags) > 0 {
+		buf = append(buf, "|#"...)
+		buf = appendTags(buf, b.tags, false)
+		buf = appendTags(buf, tags, len(b.tags) > 0)
+	}
+	return buf
+}
+
+func appendTags(buf []byte, tags []string, needSep bool) []byte {
+	for _, tag := range tags {
+		if needSep {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, tag...)
+		needSep = true
+	}
+	return buf
+}
+
+func (b *packetBackend) flushLocked() error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	// The lines are dropped on failure, such that the buffer doesn't grow while the agent is unreachable.
+	_, err := b.conn.Write(b.buf)
+	dropped := len(b.buf)
+	b.buf = b.buf[:0]
+	return errors.Wrapf(err, "unable to send statsd packet, dropped %d bytes", dropped)
+}
+
+func (b *packetBackend) flushLoop(interval time.Duration) {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastWarning time.Time
+	failures := 0
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			err := b.Flush()
+			if err == nil {
+				continue
+			}
+
+			failures++
+			if now := time.Now(); now.Sub(lastWarning) >= flushWarningInterval {
+				log(nil, err).
+					WithField("failures", failures).
+					Warn("unable to flush statsd packets")
+				lastWarning = now
+				failures = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenUDP(t *testing.T) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, DefaultUDSMaxPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestUDPBackend(t *testing.T) {
+	defer restoreStatsdTagsValue()(os.Getenv(StatsdDefaultTags))
+	require.NoError(t, os.Setenv(StatsdDefaultTags, ""))
+
+	conn := listenUDP(t)
+	ctx := context.Background()
+
+	b, err := NewUDPBackend(conn.LocalAddr().String(), "goose.", []string{"env:test"})
+	require.NoError(t, err)
+	defer b.(*packetBackend).Close()
+
+	require.NoError(t, b.Gauge(ctx, "gauge", 1.5, []string{"a:b"}, 1))
+	require.NoError(t, b.Count(ctx, "count", 2, nil, 1))
+	require.NoError(t, b.Histogram(ctx, "histogram", 3, []string{"a:b", "c:d"}, 1))
+	require.NoError(t, b.Distribution(ctx, "distribution", 4.25, nil, 1))
+	require.NoError(t, b.Set(ctx, "set", "user1", nil, 1))
+	require.NoError(t, b.Timing(ctx, "timing", 1500*time.Microsecond, nil, 1))
+	require.NoError(t, b.(*packetBackend).Flush())
+
+	assert.Equal(t, strings.Join([]string{
+		"goose.gauge:1.5|g|#env:test,a:b",
+		"goose.count:2|c|#env:test",
+		"goose.histogram:3|h|#env:test,a:b,c:d",
+		"goose.distribution:4.25|d|#env:test",
+		"goose.set:user1|s|#env:test",
+		"goose.timing:1.5|ms|#env:test",
+	}, "\n"), readPacket(t, conn))
+}
+
+func TestUDPBackend_sampleRate(t *testing.T) {
+	conn := listenUDP(t)
+	ctx := context.Background()
+
+	udpConn, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	b := newPacketBackend(udpConn, "", nil, DefaultUDPMaxPacketSize, time.Hour)
+	defer b.Close()
+
+	// Sample rates of 0 are never sent, but 1 is always sent
+	require.NoError(t, b.Count(ctx, "dropped", 1, nil, 0))
+	require.NoError(t, b.Count(ctx, "sent", 1, nil, 1))
+	require.NoError(t, b.Flush())
+
+	assert.Equal(t, "sent:1|c", readPacket(t, conn))
+
+	assert.Equal(t, "sampled:1|c|@0.5", string(b.appendLine(nil, "sampled", "1", "c", nil, 0.5)))
+}
+
+func TestUDPBackend_batching(t *testing.T) {
+	conn := listenUDP(t)
+	ctx := context.Background()
+
+	udpConn, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	// Room for two "metric:1|c" lines, including the separator
+	b := newPacketBackend(udpConn, "", nil, 21, time.Hour)
+	defer b.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Count(ctx, "metric", 1, nil, 1))
+	}
+	assert.Equal(t, "metric:1|c\nmetric:1|c", readPacket(t, conn))
+
+	require.NoError(t, b.Flush())
+	assert.Equal(t, "metric:1|c", readPacket(t, conn))
+}
+
+func TestUDPBackend_lineTooLarge(t *testing.T) {
+	conn := listenUDP(t)
+	ctx := context.Background()
+
+	udpConn, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	b := newPacketBackend(udpConn, "", nil, 21, time.Hour)
+	defer b.Close()
+
+	require.NoError(t, b.Count(ctx, "metric", 1, nil, 1))
+	err = b.Count(ctx, "metric", 1, []string{"too:long:tag"}, 1)
+	assert.ErrorIs(t, err, ErrLineTooLarge)
+	assert.EqualError(t, err, "dropped metric (24 bytes): statsd line larger than the maximum packet size")
+
+	// The buffered lines are kept
+	require.NoError(t, b.Flush())
+	assert.Equal(t, "metric:1|c", readPacket(t, conn))
+}
+
+func TestUDPBackend_flushInterval(t *testing.T) {
+	conn := listenUDP(t)
+
+	udpConn, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	b := newPacketBackend(udpConn, "", nil, DefaultUDPMaxPacketSize, 10*time.Millisecond)
+	defer b.Close()
+
+	require.NoError(t, b.Count(context.Background(), "metric", 1, nil, 1))
+	assert.Equal(t, "metric:1|c", readPacket(t, conn))
+}
+
+func TestUDSBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dsd.socket")
+	conn, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	b, err := NewUDSBackend(path, "", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Count(context.Background(), "metric", 1, []string{"a:b"}, 1))
+	require.NoError(t, b.(*packetBackend).Close())
+
+	assert.Equal(t, "metric:1|c|#a:b", readPacket(t, conn))
+}
@@ -0,0 +1,99 @@
+package trace
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Shopify/goose/statsd"
+)
+
+// Exporter receives the sampled spans once they end.
+// ExportSpan is called synchronously by Span.End, it should not block.
+type Exporter interface {
+	ExportSpan(ctx context.Context, s SpanData)
+}
+
+// ExporterFunc is a function implementing Exporter.
+type ExporterFunc func(ctx context.Context, s SpanData)
+
+func (f ExporterFunc) ExportSpan(ctx context.Context, s SpanData) {
+	f(ctx, s)
+}
+
+var exporter Exporter
+
+// SetExporter sets the Exporter receiving the finished spans. Spans are not exported by default.
+// It should be called once at application startup.
+func SetExporter(e Exporter) {
+	exporter = e
+}
+
+func getExporter() Exporter {
+	return exporter
+}
+
+// NewLogExporter creates an Exporter logging spans at the debug level.
+// The log entry contains the fields of the span's Context, including its trace and span IDs.
+func NewLogExporter() Exporter {
+	return ExporterFunc(func(ctx context.Context, s SpanData) {
+		entry := log(ctx, s.Err).
+			WithField("span", s.Name).
+			WithField("duration", s.Duration()).
+			WithField("attributes", s.Attributes)
+		if s.ParentSpanID.IsValid() {
+			entry = entry.WithField("parentSpanID", s.ParentSpanID.String())
+		}
+		entry.Debug("span finished")
+	})
+}
+
+// MemoryExporter is an Exporter keeping the spans in memory, to be inspected by tests.
+type MemoryExporter struct {
+	l     sync.Mutex
+	spans []SpanData
+}
+
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// WithTestExporter replaces the current Exporter with a new MemoryExporter for the duration of the test.
+// The previous Exporter is restored when the test completes.
+func WithTestExporter(t statsd.TestingT) *MemoryExporter {
+	t.Helper()
+
+	prev := getExporter()
+	e := NewMemoryExporter()
+	SetExporter(e)
+	t.Cleanup(func() { SetExporter(prev) })
+	return e
+}
+
+func (e *MemoryExporter) ExportSpan(_ context.Context, s SpanData) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// Spans returns the exported spans, in the order they ended.
+func (e *MemoryExporter) Spans() []SpanData {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Find returns the first exported span with the name.
+func (e *MemoryExporter) Find(name string) (SpanData, bool) {
+	for _, s := range e.Spans() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return SpanData{}, false
+}
+
+func (e *MemoryExporter) Reset() {
+	e.l.Lock()
+	defer e.l.Unlock()
+	e.spans = nil
+}
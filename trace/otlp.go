@@ -0,0 +1,278 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// DefaultOTLPEndpoint is the traces endpoint of a local OpenTelemetry collector, using OTLP over HTTP.
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// InstrumentationName is the name of the instrumentation scope of the exported spans.
+const InstrumentationName = "github.com/Shopify/goose/trace"
+
+// OTLPConfig configures an OTLPExporter.
+type OTLPConfig struct {
+	// Endpoint is the URL spans are posted to. Defaults to DefaultOTLPEndpoint.
+	Endpoint string
+	// ServiceName is the service.name resource attribute of the spans.
+	ServiceName string
+	// Client performs the requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+	// Interval is the period at which spans are sent. Defaults to 5s.
+	Interval time.Duration
+	// MaxQueueSize is the number of spans kept until the next send, spans are dropped once reached. Defaults to 2048.
+	MaxQueueSize int
+	// MaxBatchSize is the maximum number of spans per request. Defaults to 512.
+	MaxBatchSize int
+}
+
+// OTLPExporter is an Exporter sending the spans to an OpenTelemetry collector, using OTLP over HTTP with
+// JSON encoding. Spans are queued, and sent every interval while the exporter runs as a genmain.Component.
+type OTLPExporter struct {
+	tomb   tomb.Tomb
+	config OTLPConfig
+
+	l       sync.Mutex
+	queue   []SpanData
+	dropped int
+}
+
+// NewOTLPExporter creates a new OTLPExporter.
+func NewOTLPExporter(config OTLPConfig) *OTLPExporter {
+	if config.Endpoint == "" {
+		config.Endpoint = DefaultOTLPEndpoint
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+	if config.MaxQueueSize <= 0 {
+		config.MaxQueueSize = 2048
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 512
+	}
+	return &OTLPExporter{config: config}
+}
+
+func (e *OTLPExporter) ExportSpan(_ context.Context, s SpanData) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	if len(e.queue) >= e.config.MaxQueueSize {
+		e.dropped++
+		return
+	}
+	e.queue = append(e.queue, s)
+}
+
+func (e *OTLPExporter) Tomb() *tomb.Tomb {
+	return &e.tomb
+}
+
+// Run sends the queued spans every interval, until the exporter is killed. The remaining spans are sent upon death.
+func (e *OTLPExporter) Run() error {
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.tomb.Dying():
+			ctx, cancel := context.WithTimeout(context.Background(), e.config.Client.Timeout)
+			defer cancel()
+			return e.Flush(ctx)
+		case <-ticker.C:
+			if err := e.Flush(e.tomb.Context(context.Background())); err != nil {
+				log(nil, err).Warn("unable to export spans")
+			}
+		}
+	}
+}
+
+// Flush sends the queued spans. Spans of failed requests are dropped.
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	e.l.Lock()
+	queue := e.queue
+	dropped := e.dropped
+	e.queue = nil
+	e.dropped = 0
+	e.l.Unlock()
+
+	if dropped > 0 {
+		log(ctx, nil).WithField("droppedSpans", dropped).Warn("span queue is full, spans were dropped")
+	}
+
+	var errs []error
+	for len(queue) > 0 {
+		n := len(queue)
+		if n > e.config.MaxBatchSize {
+			n = e.config.MaxBatchSize
+		}
+		if err := e.send(ctx, queue[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		queue = queue[n:]
+	}
+	return errors.Join(errs...)
+}
+
+func (e *OTLPExporter) send(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return fmt.Errorf("unable to encode spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send spans: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unable to send spans: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Types of the OTLP/JSON encoding, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+// Trace and span IDs are hex-encoded, and 64-bit integers are strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes,omitempty"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		TraceState        string          `json:"traceState,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusCodeError  = 2
+)
+
+func (e *OTLPExporter) request(spans []SpanData) otlpRequest {
+	var resource otlpResource
+	if e.config.ServiceName != "" {
+		resource.Attributes = []otlpAttribute{otlpAttr("service.name", e.config.ServiceName)}
+	}
+
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			TraceState:        s.SpanContext.TraceState,
+			Name:              s.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+		}
+		if s.ParentSpanID.IsValid() {
+			span.ParentSpanID = s.ParentSpanID.String()
+		}
+		for _, k := range sortedKeys(s.Attributes) {
+			span.Attributes = append(span.Attributes, otlpAttr(k, s.Attributes[k]))
+		}
+		if s.Err != nil {
+			span.Status = otlpStatus{Code: otlpStatusCodeError, Message: s.Err.Error()}
+		}
+		otlpSpans = append(otlpSpans, span)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: resource,
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: InstrumentationName},
+			Spans: otlpSpans,
+		}},
+	}}}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func otlpAttr(key string, value interface{}) otlpAttribute {
+	var v otlpValue
+	switch value := value.(type) {
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.Itoa(value)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	case string:
+		v.StringValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+	return otlpAttribute{Key: key, Value: v}
+}
@@ -0,0 +1,110 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	e := NewOTLPExporter(OTLPConfig{Endpoint: server.URL, ServiceName: "test", MaxBatchSize: 1})
+
+	sc, err := ParseTraceparent(testTraceparent)
+	require.NoError(t, err)
+	start := time.Unix(1, 0)
+	e.ExportSpan(context.Background(), SpanData{
+		Name:         "operation",
+		SpanContext:  sc,
+		ParentSpanID: SpanID{1},
+		Start:        start,
+		End:          start.Add(time.Second),
+		Attributes:   map[string]interface{}{"method": "GET", "statusCode": 500, "cached": false},
+		Err:          errors.New("failed"),
+	})
+	e.ExportSpan(context.Background(), SpanData{Name: "other", SpanContext: sc, Start: start, End: start})
+
+	require.NoError(t, e.Flush(context.Background()))
+	require.Len(t, bodies, 2) // One span per batch
+
+	assert.JSONEq(t, `{"resourceSpans": [{
+		"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "test"}}]},
+		"scopeSpans": [{
+			"scope": {"name": "github.com/Shopify/goose/trace"},
+			"spans": [{
+				"traceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"spanId": "00f067aa0ba902b7",
+				"parentSpanId": "0100000000000000",
+				"name": "operation",
+				"kind": 1,
+				"startTimeUnixNano": "1000000000",
+				"endTimeUnixNano": "2000000000",
+				"attributes": [
+					{"key": "cached", "value": {"boolValue": false}},
+					{"key": "method", "value": {"stringValue": "GET"}},
+					{"key": "statusCode", "value": {"intValue": "500"}}
+				],
+				"status": {"code": 2, "message": "failed"}
+			}]
+		}]
+	}]}`, bodies[0])
+
+	var req otlpRequest
+	require.NoError(t, json.Unmarshal([]byte(bodies[1]), &req))
+	assert.Equal(t, "other", req.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+
+	// Nothing left to send
+	require.NoError(t, e.Flush(context.Background()))
+	assert.Len(t, bodies, 2)
+}
+
+func TestOTLPExporter_errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	e := NewOTLPExporter(OTLPConfig{Endpoint: server.URL, MaxQueueSize: 1})
+	e.ExportSpan(context.Background(), SpanData{Name: "operation"})
+	e.ExportSpan(context.Background(), SpanData{Name: "dropped"})
+	assert.Len(t, e.queue, 1)
+
+	assert.EqualError(t, e.Flush(context.Background()), "unable to send spans: unexpected status 503 Service Unavailable")
+}
+
+func TestOTLPExporter_Run(t *testing.T) {
+	received := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer server.Close()
+
+	e := NewOTLPExporter(OTLPConfig{Endpoint: server.URL, Interval: time.Hour})
+	e.Tomb().Go(e.Run)
+	e.ExportSpan(context.Background(), SpanData{Name: "operation"})
+
+	// Remaining spans are sent upon death
+	e.Tomb().Kill(nil)
+	require.NoError(t, e.Tomb().Wait())
+	select {
+	case <-received:
+	default:
+		t.Fatal("spans were not sent")
+	}
+}
@@ -0,0 +1,94 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Extract parses the traceparent and tracestate headers. The tracestate is dropped if the traceparent is invalid,
+// or if it is too long to be propagated.
+func Extract(h http.Header) (SpanContext, error) {
+	sc, err := ParseTraceparent(h.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, err
+	}
+
+	// Multiple tracestate headers are equivalent to a single comma-separated one.
+	if state := strings.Join(h.Values(TracestateHeader), ","); len(state) <= maxTracestateLength {
+		sc.TraceState = state
+	}
+	return sc, nil
+}
+
+// Inject sets the traceparent and tracestate headers from the SpanContext attached to the Context.
+// The headers are left untouched if there is none.
+func Inject(ctx context.Context, h http.Header) {
+	sc, ok := FromContext(ctx)
+	if !ok || !sc.IsValid() {
+		return
+	}
+
+	h.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set(TracestateHeader, sc.TraceState)
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
+
+// ContinueFromHeader attaches a new SpanContext to the Context, continuing the trace of the traceparent header,
+// or starting a new trace if the header is missing or invalid.
+// The first span started with StartRequest uses this SpanContext, such that it is the child of the caller's span.
+func ContinueFromHeader(ctx context.Context, h http.Header) context.Context {
+	parent, err := Extract(h)
+	if err != nil && h.Get(TraceparentHeader) != "" {
+		log(ctx, err).Debug("ignoring invalid traceparent header")
+	}
+	return withContextSpan(ctx, contextSpan{
+		sc:      NewChild(parent),
+		pending: true,
+		parent:  parent.SpanID,
+	})
+}
+
+// Env returns the TRACEPARENT and TRACESTATE environment variables, formatted as "KEY=VALUE",
+// propagating the SpanContext attached to the Context to a child process. Empty if there is none.
+func Env(ctx context.Context) []string {
+	sc, ok := FromContext(ctx)
+	if !ok || !sc.IsValid() {
+		return nil
+	}
+
+	env := []string{TraceparentEnv + "=" + sc.Traceparent()}
+	if sc.TraceState != "" {
+		env = append(env, TracestateEnv+"="+sc.TraceState)
+	}
+	return env
+}
+
+// Transport is an http.RoundTripper setting the traceparent and tracestate headers of outbound requests
+// from the SpanContext attached to their Context.
+type Transport struct {
+	// Base is the RoundTripper performing the requests. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+}
+
+// NewTransport wraps a RoundTripper with a Transport.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if sc, ok := FromContext(r.Context()); ok && sc.IsValid() {
+		// A RoundTripper must not modify the request.
+		r = r.Clone(r.Context())
+		Inject(r.Context(), r.Header)
+	}
+	return base.RoundTrip(r)
+}
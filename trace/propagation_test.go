@@ -0,0 +1,99 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestExtract(t *testing.T) {
+	h := http.Header{}
+	h.Set(TraceparentHeader, testTraceparent)
+	h.Add(TracestateHeader, "a=1")
+	h.Add(TracestateHeader, "b=2")
+
+	sc, err := Extract(h)
+	require.NoError(t, err)
+	assert.Equal(t, testTraceparent, sc.Traceparent())
+	assert.Equal(t, "a=1,b=2", sc.TraceState)
+
+	h.Set(TracestateHeader, "a="+strings.Repeat("x", maxTracestateLength))
+	sc, err = Extract(h)
+	require.NoError(t, err)
+	assert.Empty(t, sc.TraceState)
+
+	h.Del(TraceparentHeader)
+	_, err = Extract(h)
+	assert.Error(t, err)
+}
+
+func TestInject(t *testing.T) {
+	h := http.Header{}
+	Inject(context.Background(), h)
+	assert.Empty(t, h)
+
+	sc := NewRoot()
+	sc.TraceState = "a=1"
+	Inject(WithSpanContext(context.Background(), sc), h)
+	assert.Equal(t, sc.Traceparent(), h.Get(TraceparentHeader))
+	assert.Equal(t, "a=1", h.Get(TracestateHeader))
+}
+
+func TestContinueFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set(TraceparentHeader, testTraceparent)
+
+	sc, ok := FromContext(ContinueFromHeader(context.Background(), h))
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.NotEqual(t, "00f067aa0ba902b7", sc.SpanID.String())
+
+	h.Set(TraceparentHeader, "invalid")
+	sc, ok = FromContext(ContinueFromHeader(context.Background(), h))
+	require.True(t, ok)
+	assert.True(t, sc.IsValid())
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+}
+
+func TestEnv(t *testing.T) {
+	assert.Empty(t, Env(context.Background()))
+
+	sc := NewRoot()
+	assert.Equal(t, []string{"TRACEPARENT=" + sc.Traceparent()}, Env(WithSpanContext(context.Background(), sc)))
+
+	sc.TraceState = "a=1"
+	assert.Equal(t, []string{"TRACEPARENT=" + sc.Traceparent(), "TRACESTATE=a=1"}, Env(WithSpanContext(context.Background(), sc)))
+}
+
+func TestTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, received.Get(TraceparentHeader))
+
+	sc := NewRoot()
+	req, err = http.NewRequestWithContext(WithSpanContext(context.Background(), sc), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, sc.Traceparent(), received.Get(TraceparentHeader))
+	assert.Empty(t, req.Header.Get(TraceparentHeader)) // The original request is not modified
+}
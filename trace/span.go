@@ -0,0 +1,160 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Shopify/goose/metrics"
+	"github.com/Shopify/goose/statsd"
+)
+
+// Span is an operation within a trace, timed from its start until End is called.
+//
+//	func foo(ctx context.Context) (err error) {
+//	  ctx, span := trace.Start(ctx, "foo")
+//	  defer span.End(&err)
+//	  // ...
+//	}
+type Span struct {
+	name   string
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+	ctx    context.Context
+
+	finisher statsd.Finisher // nil if the span has no Timer.
+
+	l          sync.Mutex
+	attributes map[string]interface{}
+	ended      bool
+}
+
+// SpanData is a finished Span, as passed to the Exporter.
+type SpanData struct {
+	Name         string
+	SpanContext  SpanContext
+	ParentSpanID SpanID // Invalid for the root span of a trace.
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Err          error
+}
+
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Start starts a Span, child of the span attached to the Context if any, and returns a Context carrying it.
+// Its duration is recorded by metrics.TraceSpan, tagged with the span name.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartSpan(ctx, name, metrics.TraceSpan, statsd.Tags{"span": name})
+}
+
+// StartTimer starts a Span named after the Timer, whose duration is recorded by the Timer with the tags.
+// The tags are also added as attributes of the span.
+func StartTimer(ctx context.Context, timer *statsd.Timer, ts ...statsd.Tags) (context.Context, *Span) {
+	return StartSpan(ctx, timer.Name, timer, ts...)
+}
+
+// StartSpan starts a Span whose duration is recorded by the Timer with the tags, or not recorded if the Timer is nil.
+// The tags are also added as attributes of the span.
+func StartSpan(ctx context.Context, name string, timer *statsd.Timer, ts ...statsd.Tags) (context.Context, *Span) {
+	cs, _ := getContextSpan(ctx)
+	return startSpan(ctx, name, NewChild(cs.sc), cs.sc.SpanID, timer, ts)
+}
+
+// StartRequest is the same as StartSpan, but the span of the first call uses the SpanContext created by
+// ContinueFromHeader, which is already logged and propagated, such that the span is the child of the caller's.
+// It is meant for the spans covering the whole handling of a request.
+func StartRequest(ctx context.Context, name string, timer *statsd.Timer, ts ...statsd.Tags) (context.Context, *Span) {
+	cs, _ := getContextSpan(ctx)
+	if !cs.pending {
+		return StartSpan(ctx, name, timer, ts...)
+	}
+	return startSpan(ctx, name, cs.sc, cs.parent, timer, ts)
+}
+
+func startSpan(ctx context.Context, name string, sc SpanContext, parent SpanID, timer *statsd.Timer, ts []statsd.Tags) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s := &Span{
+		name:       name,
+		sc:         sc,
+		parent:     parent,
+		start:      time.Now(),
+		attributes: map[string]interface{}{},
+	}
+	for _, tags := range ts {
+		for k, v := range tags {
+			s.attributes[k] = v
+		}
+	}
+
+	ctx = withContextSpan(ctx, contextSpan{sc: sc, span: s})
+	s.ctx = ctx
+	if timer != nil {
+		s.finisher = timer.StartTimer(ctx, ts...)
+	}
+	return ctx, s
+}
+
+// SpanFromContext returns the Span attached to the Context by Start, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	cs, _ := getContextSpan(ctx)
+	return cs.span
+}
+
+func (s *Span) Name() string {
+	return s.name
+}
+
+func (s *Span) SpanContext() SpanContext {
+	return s.sc
+}
+
+// SetAttribute adds an attribute to the exported span. It has no effect once the span has ended.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if !s.ended {
+		s.attributes[key] = value
+	}
+}
+
+// End finishes the span, records its duration and exports it. The span is successful if errp or *errp is nil.
+// Subsequent calls have no effect.
+func (s *Span) End(errp *error) {
+	end := time.Now()
+
+	s.l.Lock()
+	if s.ended {
+		s.l.Unlock()
+		return
+	}
+	s.ended = true
+	s.l.Unlock()
+
+	var err error
+	if errp != nil {
+		err = *errp
+	}
+
+	if s.finisher != nil {
+		s.finisher.SuccessFinish(&err)
+	}
+
+	if e := getExporter(); e != nil && s.sc.IsSampled() {
+		e.ExportSpan(s.ctx, SpanData{
+			Name:         s.name,
+			SpanContext:  s.sc,
+			ParentSpanID: s.parent,
+			Start:        s.start,
+			End:          end,
+			Attributes:   s.attributes,
+			Err:          err,
+		})
+	}
+}
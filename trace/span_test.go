@@ -0,0 +1,102 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/logger"
+	"github.com/Shopify/goose/statsd"
+)
+
+func TestStart(t *testing.T) {
+	backend := statsd.WithTestBackend(t)
+	exporter := WithTestExporter(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	assert.Equal(t, parent, SpanFromContext(ctx))
+	assert.Equal(t, parent.SpanContext().SpanID.String(), logger.GetLoggableValue(ctx, logger.SpanIDKey))
+
+	func() (err error) {
+		childCtx, child := Start(ctx, "child")
+		defer child.End(&err)
+
+		assert.Equal(t, parent.SpanContext().TraceID, child.SpanContext().TraceID)
+		assert.Equal(t, child.SpanContext().SpanID.String(), logger.GetLoggableValue(childCtx, logger.SpanIDKey))
+		child.SetAttribute("key", "value")
+		return errors.New("failed")
+	}()
+	parent.End(nil)
+	parent.End(nil) // No effect
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+
+	child := spans[0]
+	assert.Equal(t, "child", child.Name)
+	assert.Equal(t, parent.SpanContext().SpanID, child.ParentSpanID)
+	assert.Equal(t, map[string]interface{}{"span": "child", "key": "value"}, child.Attributes)
+	assert.EqualError(t, child.Err, "failed")
+	assert.True(t, child.End.After(child.Start))
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.False(t, spans[1].ParentSpanID.IsValid())
+	assert.NoError(t, spans[1].Err)
+
+	assert.Len(t, backend.Find(statsd.DistributionType, "trace.span", "span:child", "success:false"), 1)
+	assert.Len(t, backend.Find(statsd.DistributionType, "trace.span", "span:parent", "success:true"), 1)
+}
+
+func TestStartTimer(t *testing.T) {
+	backend := statsd.WithTestBackend(t)
+	exporter := WithTestExporter(t)
+
+	timer := &statsd.Timer{Name: "operation"}
+	_, span := StartTimer(context.Background(), timer, statsd.Tags{"method": "foo"})
+	span.End(nil)
+
+	assert.Len(t, backend.Find(statsd.DistributionType, "operation", "method:foo", "success:true"), 1)
+	s, ok := exporter.Find("operation")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"method": "foo"}, s.Attributes)
+
+	// Without a Timer, the duration isn't recorded
+	_, span = StartSpan(context.Background(), "untimed", nil)
+	span.End(nil)
+	assert.Len(t, exporter.Spans(), 2)
+	// Only the "operation" metric was recorded
+	assert.Len(t, backend.Metrics(), 1)
+}
+
+func TestStartRequest(t *testing.T) {
+	WithTestExporter(t)
+
+	h := http.Header{}
+	h.Set(TraceparentHeader, testTraceparent)
+	ctx := ContinueFromHeader(context.Background(), h)
+	sc, _ := FromContext(ctx)
+
+	ctx, span := StartRequest(ctx, "request", nil)
+	assert.Equal(t, sc, span.SpanContext())
+	assert.Equal(t, "00f067aa0ba902b7", span.parent.String())
+
+	// Only the first span reuses the SpanContext
+	_, child := StartRequest(ctx, "child", nil)
+	assert.Equal(t, sc.TraceID, child.SpanContext().TraceID)
+	assert.NotEqual(t, sc.SpanID, child.SpanContext().SpanID)
+	assert.Equal(t, sc.SpanID, child.parent)
+}
+
+func TestSpanNotSampled(t *testing.T) {
+	exporter := WithTestExporter(t)
+
+	sc := NewRoot()
+	sc.Flags = 0
+	_, span := Start(WithSpanContext(context.Background(), sc), "operation")
+	span.End(nil)
+	assert.Empty(t, exporter.Spans())
+}
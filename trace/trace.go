@@ -0,0 +1,212 @@
+// Package trace propagates W3C Trace Context (https://www.w3.org/TR/trace-context/) across HTTP services
+// and the processes they spawn, such that the log lines of a request can be correlated by trace ID.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Shopify/goose/logger"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+
+	// Environment variables passed to child processes, as used by OpenTelemetry for non-HTTP carriers.
+	TraceparentEnv = "TRACEPARENT"
+	TracestateEnv  = "TRACESTATE"
+)
+
+// FlagSampled is the trace flag set when the caller may have recorded the trace.
+const FlagSampled byte = 0x01
+
+// maxTracestateLength is the length above which a tracestate is dropped rather than propagated.
+const maxTracestateLength = 512
+
+var log = logger.New("trace")
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID identifies a trace, shared by all the spans of a request across services.
+type TraceID [16]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifies a span, a unit of work within a trace.
+type SpanID [8]byte
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// NewTraceID generates a random TraceID.
+func NewTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		randomBytes(t[:])
+	}
+	return t
+}
+
+// NewSpanID generates a random SpanID.
+func NewSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		randomBytes(s[:])
+	}
+	return s
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.Wrap(err, "unable to generate random trace id"))
+	}
+}
+
+// SpanContext is the part of a span propagated to other services.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string // Vendor-specific data, propagated as-is.
+}
+
+// IsValid returns whether both the TraceID and the SpanID are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent formats the SpanContext as a version 00 traceparent header.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// LogFields implements logger.Loggable.
+func (sc SpanContext) LogFields() logrus.Fields {
+	return logrus.Fields{
+		logger.TraceIDKey: sc.TraceID.String(),
+		logger.SpanIDKey:  sc.SpanID.String(),
+	}
+}
+
+// NewRoot creates the SpanContext of a new, sampled trace.
+func NewRoot() SpanContext {
+	return SpanContext{
+		TraceID: NewTraceID(),
+		SpanID:  NewSpanID(),
+		Flags:   FlagSampled,
+	}
+}
+
+// NewChild creates a SpanContext in the same trace as parent, with a new SpanID.
+// A new trace is started if parent is invalid.
+func NewChild(parent SpanContext) SpanContext {
+	if !parent.IsValid() {
+		return NewRoot()
+	}
+	parent.SpanID = NewSpanID()
+	return parent
+}
+
+// ParseTraceparent parses a traceparent header, such as "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// Versions above 00 are parsed as version 00, ignoring the additional fields, as the specification requires.
+func ParseTraceparent(s string) (SpanContext, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 {
+		return SpanContext{}, errors.Wrapf(ErrInvalidTraceparent, "%q", s)
+	}
+
+	version, ok := decodeHex(parts[0], 1)
+	if !ok || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return SpanContext{}, errors.Wrapf(ErrInvalidTraceparent, "%q: unsupported version", s)
+	}
+
+	var sc SpanContext
+	traceID, ok := decodeHex(parts[1], len(sc.TraceID))
+	if !ok {
+		return SpanContext{}, errors.Wrapf(ErrInvalidTraceparent, "%q: invalid trace id", s)
+	}
+	spanID, ok := decodeHex(parts[2], len(sc.SpanID))
+	if !ok {
+		return SpanContext{}, errors.Wrapf(ErrInvalidTraceparent, "%q: invalid parent id", s)
+	}
+	flags, ok := decodeHex(parts[3], 1)
+	if !ok {
+		return SpanContext{}, errors.Wrapf(ErrInvalidTraceparent, "%q: invalid trace flags", s)
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, errors.Wrapf(ErrInvalidTraceparent, "%q: all-zero id", s)
+	}
+	return sc, nil
+}
+
+// decodeHex decodes exactly n bytes of lowercase hexadecimal.
+func decodeHex(s string, n int) ([]byte, bool) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+type spanContextKey struct{}
+
+// contextSpan is the value attached to a Context.
+type contextSpan struct {
+	sc   SpanContext
+	span *Span // The Span of sc, nil if sc was not created by Start.
+
+	// pending is true when sc was created by ContinueFromHeader, and is yet to be used by StartRequest.
+	pending bool
+	parent  SpanID
+}
+
+// WithSpanContext attaches the SpanContext to the Context, and adds its trace and span IDs to the log fields.
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return withContextSpan(ctx, contextSpan{sc: sc})
+}
+
+func withContextSpan(ctx context.Context, cs contextSpan) context.Context {
+	ctx = context.WithValue(ctx, spanContextKey{}, cs)
+	return logger.WithLoggable(ctx, cs.sc)
+}
+
+func getContextSpan(ctx context.Context) (contextSpan, bool) {
+	if ctx == nil {
+		return contextSpan{}, false
+	}
+	cs, ok := ctx.Value(spanContextKey{}).(contextSpan)
+	return cs, ok
+}
+
+// FromContext returns the SpanContext attached to the Context, if any.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	cs, ok := getContextSpan(ctx)
+	return cs.sc, ok
+}
@@ -0,0 +1,73 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/goose/logger"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	// Future versions may have additional fields
+	sc, err = ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-what-the-future-holds")
+	require.NoError(t, err)
+	assert.False(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", sc.Traceparent())
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01",
+	} {
+		_, err := ParseTraceparent(s)
+		assert.True(t, errors.Is(err, ErrInvalidTraceparent), s)
+	}
+}
+
+func TestNewChild(t *testing.T) {
+	root := NewRoot()
+	assert.True(t, root.IsValid())
+	assert.True(t, root.IsSampled())
+
+	root.TraceState = "vendor=value"
+	child := NewChild(root)
+	assert.Equal(t, root.TraceID, child.TraceID)
+	assert.NotEqual(t, root.SpanID, child.SpanID)
+	assert.Equal(t, root.Flags, child.Flags)
+	assert.Equal(t, "vendor=value", child.TraceState)
+
+	assert.True(t, NewChild(SpanContext{}).IsValid())
+}
+
+func TestWithSpanContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	sc := NewRoot()
+	ctx := WithSpanContext(context.Background(), sc)
+
+	got, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, sc, got)
+
+	fields := logger.GetLoggableValues(ctx)
+	assert.Equal(t, sc.TraceID.String(), fields[logger.TraceIDKey])
+	assert.Equal(t, sc.SpanID.String(), fields[logger.SpanIDKey])
+}